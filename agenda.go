@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// localHolidays is loadHolidays without network access, for the timer
+// screen: a holiday feed given as a URL is left out.
+func (c config) localHolidays() holidaySet {
+	if isCalDAVURL(c.holidayCalendar) {
+		c.holidayCalendar = ""
+	}
+	h, _ := c.loadHolidays()
+	return h
+}
+
+// agendaView summarises what is left of today's plan and schedules, e.g.
+// "Today: 2 of 4 left · next 14:00 Review (25:00)".
+func (m model) agendaView(now time.Time) string {
+	y, mo, d := now.Date()
+	midnight := time.Date(y, mo, d, 0, 0, 0, 0, now.Location())
+	today := func(list []upcoming) []upcoming {
+		var out []upcoming
+		for _, u := range list {
+			if u.at.Before(midnight.AddDate(0, 0, 1)) {
+				out = append(out, u)
+			}
+		}
+		return out
+	}
+
+	left := today(upcomingSchedules(m.agenda, m.holidays, now))
+	if len(left) == 0 {
+		return ""
+	}
+	total := len(today(upcomingSchedules(m.agenda, m.holidays, midnight.Add(-time.Nanosecond))))
+
+	next := left[0]
+	what := next.at.Format("15:04")
+	if next.sc.label != "" {
+		what += " " + next.sc.label
+	}
+	return placeText(fmt.Sprintf("Today: %d of %d left · next %s (%s)",
+		len(left), total, what, formatDuration(next.sc.duration)), m.rowWidth())
+}
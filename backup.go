@@ -0,0 +1,213 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// backupRoot maps an archive prefix to where it lives on this machine.
+type backupRoot struct {
+	prefix string
+	path   string
+	dir    bool
+}
+
+// backupRoots lists state before data because on macOS it sits inside the
+// data directory, and each file is only archived under the first root
+// found.
+func backupRoots() []backupRoot {
+	return []backupRoot{
+		{"config.ini", configFile(), false},
+		{"state", stateDir(), true},
+		{"data", dataDir(), true},
+	}
+}
+
+// writeBackup archives the config file, history and journal as a gzipped
+// tar and returns the names it wrote.
+func writeBackup(w io.Writer) ([]string, error) {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	seen := map[string]bool{}
+	var names []string
+
+	for _, root := range backupRoots() {
+		err := filepath.WalkDir(root.path, func(p string, d fs.DirEntry, err error) error {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			if err != nil || d.IsDir() || seen[p] {
+				return err
+			}
+			seen[p] = true
+			rel, err := filepath.Rel(root.path, p)
+			if err != nil {
+				return err
+			}
+			name := path.Join(root.prefix, filepath.ToSlash(rel))
+			if err := addToArchive(tw, name, p); err != nil {
+				return err
+			}
+			names = append(names, name)
+			return nil
+		})
+		if err != nil {
+			return names, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return names, err
+	}
+	return names, gz.Close()
+}
+
+func addToArchive(tw *tar.Writer, name, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil || !fi.Mode().IsRegular() {
+		return err
+	}
+	hdr, err := tar.FileInfoHeader(fi, "")
+	if err != nil {
+		return err
+	}
+	hdr.Name = name
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// restoreBackup writes the files of an archive back to their places,
+// overwriting existing ones. Files missing from the archive are left
+// alone.
+func restoreBackup(r io.Reader) ([]string, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a %s backup: %w", appName, err)
+	}
+	tr := tar.NewReader(gz)
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return names, nil
+		}
+		if err != nil {
+			return names, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		dest, ok := restorePath(hdr.Name)
+		if !ok {
+			return names, fmt.Errorf("unexpected file %q in backup", hdr.Name)
+		}
+		if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+			return names, err
+		}
+		f, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, hdr.FileInfo().Mode().Perm())
+		if err != nil {
+			return names, err
+		}
+		_, err = io.Copy(f, tr)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return names, err
+		}
+		names = append(names, hdr.Name)
+	}
+}
+
+// restorePath maps an archive name to a local path, refusing anything
+// that would land outside the roots.
+func restorePath(name string) (string, bool) {
+	for _, root := range backupRoots() {
+		if !root.dir {
+			if name == root.prefix {
+				return root.path, true
+			}
+			continue
+		}
+		rel, ok := strings.CutPrefix(name, root.prefix+"/")
+		if ok && filepath.IsLocal(filepath.FromSlash(rel)) {
+			return filepath.Join(root.path, filepath.FromSlash(rel)), true
+		}
+	}
+	return "", false
+}
+
+// runBackupCommand handles "backup [FILE|-]". The archive is not
+// encrypted; write it to stdout and pipe it through age or gpg for that.
+func runBackupCommand(args []string) int {
+	if len(args) > 1 {
+		return usage("backup")
+	}
+	file := fmt.Sprintf("%s-backup-%s.tar.gz", appName, time.Now().Format(time.DateOnly))
+	if len(args) == 1 {
+		file = args[0]
+	}
+
+	var w io.Writer = os.Stdout
+	if file != "-" {
+		f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		defer f.Close()
+		w = f
+	}
+	names, err := writeBackup(w)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	if file != "-" {
+		fmt.Printf("Backed up %d files to %s\n", len(names), file)
+	}
+	return 0
+}
+
+// runRestoreCommand handles "restore FILE|-".
+func runRestoreCommand(args []string) int {
+	if len(args) != 1 {
+		return usage("restore")
+	}
+
+	var r io.Reader = os.Stdin
+	if args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		defer f.Close()
+		r = f
+	}
+	names, err := restoreBackup(r)
+	for _, n := range names {
+		fmt.Println("restored", n)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	return 0
+}
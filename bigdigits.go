@@ -0,0 +1,72 @@
+package main
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// bigGlyphs are five rows tall. '#' marks a filled cell.
+var bigGlyphs = map[rune][5]string{
+	'0': {"####", "#  #", "#  #", "#  #", "####"},
+	'1': {"  # ", " ## ", "  # ", "  # ", " ###"},
+	'2': {"####", "   #", "####", "#   ", "####"},
+	'3': {"####", "   #", " ###", "   #", "####"},
+	'4': {"#  #", "#  #", "####", "   #", "   #"},
+	'5': {"####", "#   ", "####", "   #", "####"},
+	'6': {"####", "#   ", "####", "#  #", "####"},
+	'7': {"####", "   #", "  # ", " #  ", " #  "},
+	'8': {"####", "#  #", "####", "#  #", "####"},
+	'9': {"####", "#  #", "####", "   #", "####"},
+	':': {" ", "#", " ", "#", " "},
+	'+': {"   ", " # ", "###", " # ", "   "},
+	'%': {"#  #", "  # ", " #  ", "#   ", "#  #"},
+}
+
+// bigText renders s in block digits, drawn with fill. Characters without a
+// glyph are dropped.
+func bigText(s, fill string) string {
+	var rows [5]strings.Builder
+	first := true
+	for _, r := range s {
+		g, ok := bigGlyphs[r]
+		if !ok {
+			continue
+		}
+		for i, line := range g {
+			if !first {
+				rows[i].WriteString(" ")
+			}
+			rows[i].WriteString(line)
+		}
+		first = false
+	}
+	lines := make([]string, len(rows))
+	for i := range rows {
+		lines[i] = strings.ReplaceAll(rows[i].String(), "#", fill)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// bigReadout is the readout in block digits, or "" when it doesn't fit
+// the row.
+func (m model) bigReadout() string {
+	fill := "█"
+	if m.theme.asciiBar {
+		fill = "#"
+	}
+	big := bigText(m.readout(), fill)
+	if lipgloss.Width(big) > m.rowWidth() {
+		return ""
+	}
+	if m.inOvertime() {
+		return overtimeStyle.Render(big)
+	}
+	return statusMessageStyle.Render(big)
+}
+
+func (m model) toggleBigDigits() (tea.Model, tea.Cmd) {
+	m.bigDigits = !m.bigDigits
+	return m, nil
+}
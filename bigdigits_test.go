@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codytheroux96/progress-timer/timer"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// golden compares got with testdata/name, or rewrites the file with -update.
+func golden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("%s differs from the golden file:\n%s\nwant:\n%s", name, got, want)
+	}
+}
+
+func TestBigGlyphs(t *testing.T) {
+	var b strings.Builder
+	for _, r := range "0123456789:+%" {
+		b.WriteString(string(r) + "\n" + bigText(string(r), "█") + "\n\n")
+	}
+	for _, s := range []string{"12:34", "01:30:00", "+05:00", "42%", "1x2"} {
+		b.WriteString(s + "\n" + bigText(s, "#") + "\n\n")
+	}
+	golden(t, "bigdigits.golden", b.String())
+}
+
+func TestBigGlyphRowsMatch(t *testing.T) {
+	for r, g := range bigGlyphs {
+		for i, row := range g {
+			if len(row) != len(g[0]) {
+				t.Errorf("glyph %q: row %d is %d wide, want %d", r, i, len(row), len(g[0]))
+			}
+		}
+	}
+}
+
+func TestBigReadoutFits(t *testing.T) {
+	tests := []struct {
+		width  int
+		remain time.Duration
+		shown  bool
+	}{
+		{0, 90 * time.Minute, true}, // width not yet known: the layout's own
+		{21, 12*time.Minute + 34*time.Second, true},
+		{20, 12*time.Minute + 34*time.Second, false},
+		{33, 90 * time.Minute, true},
+		{32, 90 * time.Minute, false},
+	}
+	for _, tt := range tests {
+		m := model{width: tt.width, countdown: timer.Countdown{Duration: 2 * time.Hour}, timeRemaining: tt.remain}
+		if got := m.bigReadout() != ""; got != tt.shown {
+			t.Errorf("%s at width %d: shown %v, want %v", formatDuration(tt.remain), tt.width, got, tt.shown)
+		}
+	}
+}
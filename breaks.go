@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// breakTip is something to do on a break, for as long as the break lasts
+// or for its own few minutes.
+type breakTip struct {
+	text     string
+	duration time.Duration // 0 for the rest of the break
+}
+
+// parseBreakTips reads a comma-separated list such as
+// "Stretch 2m, Drink some water, Look outside". A trailing Go duration is
+// how long the tip lasts before the next one shows.
+func parseBreakTips(s string) ([]breakTip, error) {
+	var tips []breakTip
+	for _, f := range strings.Split(s, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		tip := breakTip{text: f}
+		if i := strings.LastIndex(f, " "); i > 0 {
+			if d, err := time.ParseDuration(f[i+1:]); err == nil {
+				if d < time.Second {
+					return nil, fmt.Errorf("%q: a tip lasts at least a second", f)
+				}
+				tip = breakTip{text: strings.TrimSpace(f[:i]), duration: d}
+			}
+		}
+		tips = append(tips, tip)
+	}
+	return tips, nil
+}
+
+// onBreak reports whether the timer is a pomodoro break or an interval
+// rest.
+func (m model) onBreak() bool {
+	return m.pomodoro != nil && m.pomodoro.phase != work ||
+		m.intervals != nil && m.intervals.kind == intervalRest
+}
+
+// breakTip is the tip for now and, for one with its own duration, the
+// time left on it. Each break starts one tip further down the list than
+// the last, and a timed tip hands over to the next when it runs out.
+func (m model) breakTip() (breakTip, time.Duration, bool) {
+	if !m.onBreak() || len(m.breakTips) == 0 {
+		return breakTip{}, 0, false
+	}
+	n := 0
+	if m.pomodoro != nil {
+		n = m.pomodoro.completed - 1
+	} else {
+		n = m.intervals.round - 1
+	}
+	elapsed := m.countdown.Duration - m.timeRemaining
+	for i := 0; ; i++ {
+		tip := m.breakTips[(n+i)%len(m.breakTips)]
+		if tip.duration == 0 || elapsed < tip.duration || i == len(m.breakTips)-1 {
+			return tip, max(tip.duration-elapsed, 0), true
+		}
+		elapsed -= tip.duration
+	}
+}
+
+// breakTipView is the tip line under the phase, e.g.
+// "Try: Stretch · 01:20".
+func (m model) breakTipView() string {
+	tip, left, ok := m.breakTip()
+	if !ok {
+		return ""
+	}
+	line := "Try: " + tip.text
+	if tip.duration > 0 {
+		line += " · " + formatDuration(left)
+	}
+	return lipgloss.NewStyle().Italic(true).Render(placeText(line, m.rowWidth()))
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const caldavTimeout = 15 * time.Second
+
+type caldavClient struct {
+	url      string
+	username string
+	password string
+}
+
+func (c caldavClient) do(ctx context.Context, method, url string, body io.Reader, header map[string]string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	return http.DefaultClient.Do(req)
+}
+
+// calendarQuery asks for the events overlapping the time range %[1]s to
+// %[2]s, with recurring ones expanded by the server into their instances
+// in it. Servers that ignore expand send the recurring event itself,
+// which events then expands.
+const calendarQuery = `<?xml version="1.0" encoding="utf-8"?>
+<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop>
+    <c:calendar-data>
+      <c:expand start="%[1]s" end="%[2]s"/>
+    </c:calendar-data>
+  </d:prop>
+  <c:filter>
+    <c:comp-filter name="VCALENDAR">
+      <c:comp-filter name="VEVENT">
+        <c:time-range start="%[1]s" end="%[2]s"/>
+      </c:comp-filter>
+    </c:comp-filter>
+  </c:filter>
+</c:calendar-query>`
+
+type multistatus struct {
+	Responses []struct {
+		CalendarData string `xml:"propstat>prop>calendar-data"`
+	} `xml:"response"`
+}
+
+// events fetches the events overlapping [from, to) from the collection.
+func (c caldavClient) events(from, to time.Time) ([]calEvent, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), caldavTimeout)
+	defer cancel()
+
+	const stamp = "20060102T150405Z"
+	body := fmt.Sprintf(calendarQuery, from.UTC().Format(stamp), to.UTC().Format(stamp))
+	resp, err := c.do(ctx, "REPORT", c.url, strings.NewReader(body), map[string]string{
+		"Content-Type": "application/xml; charset=utf-8",
+		"Depth":        "1",
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, fmt.Errorf("caldav REPORT %s: %s", c.url, resp.Status)
+	}
+
+	var ms multistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		return nil, fmt.Errorf("caldav REPORT %s: %w", c.url, err)
+	}
+
+	var events []calEvent
+	for _, r := range ms.Responses {
+		evs, err := parseICS(strings.NewReader(r.CalendarData))
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, evs...)
+	}
+	return expandEvents(events, from, to), nil
+}
+
+// newEventUID makes the UID of a pushed session. It is made once per
+// session and kept across retries, so a push that reached the server but
+// timed out on the way back can't create the event twice.
+func newEventUID() string {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+// putSession stores a finished session as the event uid in the
+// collection. An event already stored under uid, by an earlier attempt,
+// counts as success.
+func (c caldavClient) putSession(uid, summary string, start, end time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), caldavTimeout)
+	defer cancel()
+
+	const stamp = "20060102T150405Z"
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//" + appName + "//EN",
+		"BEGIN:VEVENT",
+		"UID:" + uid + "@" + appName,
+		"DTSTAMP:" + time.Now().UTC().Format(stamp),
+		"DTSTART:" + start.UTC().Format(stamp),
+		"DTEND:" + end.UTC().Format(stamp),
+		"SUMMARY:" + escapeICS(summary),
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+
+	url := strings.TrimSuffix(c.url, "/") + "/" + uid + ".ics"
+	resp, err := c.do(ctx, http.MethodPut, url, strings.NewReader(ics), map[string]string{
+		"Content-Type":  "text/calendar; charset=utf-8",
+		"If-None-Match": "*",
+	})
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 && resp.StatusCode != http.StatusPreconditionFailed {
+		return fmt.Errorf("caldav PUT %s: %s", url, resp.Status)
+	}
+	return nil
+}
+
+func escapeICS(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+func isCalDAVURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// pushSession records the completed timer in the CalDAV calendar when
+// caldav.push is enabled.
+func (m model) pushSession() tea.Cmd {
+	if !m.caldavPush || m.caldav.url == "" {
+		return nil
+	}
+	c, clk, start, end, uid := m.caldav, m.clock, m.countdown.Started, m.clock.Now(), newEventUID()
+	summary := m.label
+	if summary == "" {
+		summary = "Timer (" + formatDuration(m.countdown.Duration) + ")"
+	}
+	key := m.deliveryKey(eventComplete.String())
+	if m.dryRun {
+		return m.dispatch.dryRun("Calendar sync", key, fmt.Sprintf("%s, %s to %s, to %s", summary,
+			start.Format(time.RFC3339), end.Format(time.RFC3339), c.url))
+	}
+	return m.dispatch.deliver("Calendar sync", key, caldavTimeout, func() error {
+		err := c.putSession(uid, summary, start, end)
+		if err == nil {
+			return nil
+		}
+		p := pendingPush{next: clk.Now().Add(retryDelay(0)), attempts: 1, start: start, end: end, uid: uid, summary: summary}
+		if queuePush(p) != nil {
+			return err
+		}
+		return fmt.Errorf("%w; queued to retry later", err)
+	})
+}
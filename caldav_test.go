@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCalDAVEvents(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		query = string(body)
+		// A server that ignores expand and sends the recurring event.
+		data := ics(
+			"BEGIN:VEVENT",
+			"DTSTART:20260302T090000Z",
+			"DURATION:PT15M",
+			"RRULE:FREQ=DAILY",
+			"SUMMARY:Standup",
+			"END:VEVENT",
+		)
+		w.WriteHeader(http.StatusMultiStatus)
+		io.WriteString(w, `<d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav"><d:response><d:propstat><d:prop><c:calendar-data>`)
+		xml.EscapeText(w, []byte(data))
+		io.WriteString(w, `</c:calendar-data></d:prop></d:propstat></d:response></d:multistatus>`)
+	}))
+	defer srv.Close()
+
+	from := time.Date(2026, 3, 9, 12, 0, 0, 0, time.UTC)
+	events, err := caldavClient{url: srv.URL}.events(from, from.Add(24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `<c:expand start="20260309T120000Z" end="20260310T120000Z"/>`; !strings.Contains(query, want) {
+		t.Errorf("query has no %s:\n%s", want, query)
+	}
+	if len(events) != 1 || !events[0].start.Equal(time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("got %+v, want the standup of March 10", events)
+	}
+}
+
+func TestEventFromCalendarWindow(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		query = string(body)
+		w.WriteHeader(http.StatusMultiStatus)
+		io.WriteString(w, `<d:multistatus xmlns:d="DAV:"/>`)
+	}))
+	defer srv.Close()
+
+	now := time.Date(2026, 3, 9, 12, 0, 0, 0, time.UTC)
+	eventFromCalendar(srv.URL, caldavClient{}, now)
+	if want := `start="20260309T120000Z" end="20260316T120000Z"`; !strings.Contains(query, want) {
+		t.Errorf("query has no %s:\n%s", want, query)
+	}
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type calEvent struct {
+	summary string
+	start   time.Time
+	end     time.Time
+	allDay  bool
+
+	// Recurring events keep their rule until expandEvents turns them into
+	// instances. A RECURRENCE-ID marks an instance moved or edited away
+	// from the one its UID's rule would give.
+	uid          string
+	rule         *recurrence
+	exdates      []time.Time
+	recurrenceID time.Time
+}
+
+// parseICS reads the VEVENTs of an iCalendar file. Properties of nested
+// components, such as a VALARM's SUMMARY, are ignored. Recurring events
+// are returned once, with their rule; see expandEvents.
+func parseICS(r io.Reader) ([]calEvent, error) {
+	var (
+		events []calEvent
+		cur    *calEvent
+		lines  []string
+		nested []string // components open inside the current VEVENT
+	)
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	var duration time.Duration
+	for _, line := range lines {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		name, params, _ := strings.Cut(name, ";")
+		name = strings.ToUpper(name)
+
+		switch {
+		case name == "BEGIN" && cur != nil:
+			nested = append(nested, value)
+			continue
+		case name == "END" && len(nested) > 0:
+			nested = nested[:len(nested)-1]
+			continue
+		case cur == nil && name != "BEGIN" || len(nested) > 0:
+			continue
+		}
+
+		switch name {
+		case "BEGIN":
+			if value == "VEVENT" {
+				cur, duration = &calEvent{}, 0
+			}
+		case "END":
+			if value == "VEVENT" {
+				if cur.end.IsZero() {
+					switch {
+					case duration > 0:
+						cur.end = cur.start.Add(duration)
+					case cur.allDay:
+						cur.end = cur.start.AddDate(0, 0, 1)
+					default:
+						cur.end = cur.start
+					}
+				}
+				if !cur.start.IsZero() {
+					events = append(events, *cur)
+				}
+				cur = nil
+			}
+		case "SUMMARY":
+			cur.summary = unescapeICS(value)
+		case "UID":
+			cur.uid = value
+		case "DTSTART":
+			t, allDay, err := parseICSTime(value, params)
+			if err != nil {
+				return nil, fmt.Errorf("DTSTART %q: %w", value, err)
+			}
+			cur.start, cur.allDay = t, allDay
+		case "DTEND":
+			t, _, err := parseICSTime(value, params)
+			if err != nil {
+				return nil, fmt.Errorf("DTEND %q: %w", value, err)
+			}
+			cur.end = t
+		case "DURATION":
+			d, err := parseICSDuration(value)
+			if err != nil {
+				return nil, fmt.Errorf("DURATION %q: %w", value, err)
+			}
+			duration = d
+		case "RRULE":
+			// A rule we can't follow leaves just the first occurrence,
+			// which is what the event shows without one.
+			if rule, err := parseRRule(value); err == nil {
+				cur.rule = rule
+			}
+		case "EXDATE":
+			for _, v := range strings.Split(value, ",") {
+				t, _, err := parseICSTime(v, params)
+				if err != nil {
+					return nil, fmt.Errorf("EXDATE %q: %w", v, err)
+				}
+				cur.exdates = append(cur.exdates, t)
+			}
+		case "RECURRENCE-ID":
+			t, _, err := parseICSTime(value, params)
+			if err != nil {
+				return nil, fmt.Errorf("RECURRENCE-ID %q: %w", value, err)
+			}
+			cur.recurrenceID = t
+		}
+	}
+	return events, nil
+}
+
+// parseICSTime handles UTC (…Z), TZID-qualified, floating and all-day
+// (VALUE=DATE) values. Floating times are read in the local zone.
+func parseICSTime(value, params string) (time.Time, bool, error) {
+	loc := time.Local
+	for _, p := range strings.Split(params, ";") {
+		k, v, _ := strings.Cut(p, "=")
+		if strings.EqualFold(k, "TZID") {
+			if l, err := time.LoadLocation(strings.Trim(v, `"`)); err == nil {
+				loc = l
+			}
+		}
+	}
+
+	switch {
+	case len(value) == 8:
+		t, err := time.ParseInLocation("20060102", value, time.Local)
+		return t, true, err
+	case strings.HasSuffix(value, "Z"):
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	default:
+		t, err := time.ParseInLocation("20060102T150405", value, loc)
+		return t, false, err
+	}
+}
+
+var icsDuration = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+func parseICSDuration(s string) (time.Duration, error) {
+	m := icsDuration.FindStringSubmatch(s)
+	if m == nil {
+		return 0, errors.New("not an RFC 5545 duration")
+	}
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, u := range units {
+		if m[i+2] != "" {
+			n, _ := strconv.Atoi(m[i+2])
+			d += time.Duration(n) * u
+		}
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+func unescapeICS(s string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(s)
+}
+
+// currentOrNextEvent returns the timed event in progress at now that ends
+// soonest, or else the next one to start. All-day events are skipped;
+// they make poor countdowns.
+func currentOrNextEvent(events []calEvent, now time.Time) (calEvent, bool) {
+	better := func(a, b calEvent) bool {
+		aNow, bNow := !a.start.After(now), !b.start.After(now)
+		switch {
+		case aNow != bNow:
+			return aNow
+		case aNow:
+			return a.end.Before(b.end)
+		}
+		return a.start.Before(b.start)
+	}
+
+	var best calEvent
+	found := false
+	for _, e := range events {
+		if e.allDay || !e.end.After(now) {
+			continue
+		}
+		if !found || better(e, best) {
+			best, found = e, true
+		}
+	}
+	return best, found
+}
+
+// calendarLookahead is how far ahead eventFromCalendar looks for the next
+// event, the same for a file as for a CalDAV collection.
+const calendarLookahead = 7 * 24 * time.Hour
+
+// eventFromCalendar picks the event to count down to from an .ics file,
+// or from a CalDAV collection when source is a URL.
+func eventFromCalendar(source string, c caldavClient, now time.Time) (calEvent, error) {
+	var (
+		events []calEvent
+		err    error
+	)
+	from, to := now, now.Add(calendarLookahead)
+	if isCalDAVURL(source) {
+		c.url = source
+		events, err = c.events(from, to)
+	} else if events, err = readICSFile(source); err == nil {
+		events = expandEvents(events, from, to)
+	}
+	if err != nil {
+		return calEvent{}, fmt.Errorf("%s: %w", source, err)
+	}
+	e, ok := currentOrNextEvent(events, now)
+	if !ok {
+		return calEvent{}, fmt.Errorf("%s: no current or upcoming event", source)
+	}
+	return e, nil
+}
+
+func readICSFile(path string) ([]calEvent, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseICS(f)
+}
+
+// fetchICS downloads a published iCalendar feed.
+func fetchICS(url string) ([]calEvent, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), caldavTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return parseICS(resp.Body)
+}
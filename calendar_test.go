@@ -0,0 +1,161 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ics wraps VEVENT lines in a calendar, with CRLF line ends.
+func ics(lines ...string) string {
+	all := append([]string{"BEGIN:VCALENDAR", "VERSION:2.0"}, lines...)
+	return strings.Join(append(all, "END:VCALENDAR", ""), "\r\n")
+}
+
+func mustParseICS(t *testing.T, s string) []calEvent {
+	t.Helper()
+	events, err := parseICS(strings.NewReader(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return events
+}
+
+func TestParseICSAlarmSummary(t *testing.T) {
+	events := mustParseICS(t, ics(
+		"BEGIN:VEVENT",
+		"DTSTART:20260302T090000Z",
+		"DTEND:20260302T093000Z",
+		"SUMMARY:Standup",
+		"BEGIN:VALARM",
+		"ACTION:DISPLAY",
+		"SUMMARY:Reminder",
+		"TRIGGER:-PT5M",
+		"END:VALARM",
+		"END:VEVENT",
+	))
+	if len(events) != 1 || events[0].summary != "Standup" || events[0].end.Sub(events[0].start) != 30*time.Minute {
+		t.Fatalf("got %+v, want one 30m Standup", events)
+	}
+}
+
+func TestExpandEvents(t *testing.T) {
+	utc := func(day, hour int) time.Time { return time.Date(2026, 3, day, hour, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name   string
+		lines  []string
+		from   time.Time
+		to     time.Time
+		starts []time.Time
+	}{
+		{
+			"weekly by day",
+			[]string{"DTSTART:20260302T090000Z", "DURATION:PT30M", "RRULE:FREQ=WEEKLY;BYDAY=WE,MO"},
+			utc(8, 0), utc(17, 0),
+			[]time.Time{utc(9, 9), utc(11, 9), utc(16, 9)},
+		},
+		{
+			"in progress at from",
+			[]string{"DTSTART:20260302T090000Z", "DURATION:PT30M", "RRULE:FREQ=DAILY"},
+			utc(5, 9).Add(10 * time.Minute), utc(6, 12),
+			[]time.Time{utc(5, 9), utc(6, 9)},
+		},
+		{
+			"count",
+			[]string{"DTSTART:20260302T090000Z", "DURATION:PT30M", "RRULE:FREQ=DAILY;INTERVAL=2;COUNT=3"},
+			utc(1, 0), utc(31, 0),
+			[]time.Time{utc(2, 9), utc(4, 9), utc(6, 9)},
+		},
+		{
+			"until a date",
+			[]string{"DTSTART:20260302T090000Z", "DURATION:PT30M", "RRULE:FREQ=DAILY;UNTIL=20260304"},
+			utc(1, 0), utc(31, 0),
+			[]time.Time{utc(2, 9), utc(3, 9), utc(4, 9)},
+		},
+		{
+			"exdate",
+			[]string{"DTSTART:20260302T090000Z", "DURATION:PT30M", "RRULE:FREQ=DAILY;COUNT=3", "EXDATE:20260303T090000Z"},
+			utc(1, 0), utc(31, 0),
+			[]time.Time{utc(2, 9), utc(4, 9)},
+		},
+		{
+			"monthly skips short months",
+			[]string{"DTSTART:20260131T090000Z", "DURATION:PT30M", "RRULE:FREQ=MONTHLY;COUNT=3"},
+			time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+			[]time.Time{time.Date(2026, 1, 31, 9, 0, 0, 0, time.UTC), utc(31, 9), time.Date(2026, 5, 31, 9, 0, 0, 0, time.UTC)},
+		},
+		{
+			"unsupported rule keeps the first",
+			[]string{"DTSTART:20260302T090000Z", "DURATION:PT30M", "RRULE:FREQ=MONTHLY;BYDAY=1MO"},
+			utc(1, 0), utc(31, 0),
+			[]time.Time{utc(2, 9)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := append(append([]string{"BEGIN:VEVENT", "SUMMARY:Meeting"}, tt.lines...), "END:VEVENT")
+			got := expandEvents(mustParseICS(t, ics(lines...)), tt.from, tt.to)
+			if len(got) != len(tt.starts) {
+				t.Fatalf("got %d instances, want %d: %+v", len(got), len(tt.starts), got)
+			}
+			for i, e := range got {
+				if !e.start.Equal(tt.starts[i]) || e.end.Sub(e.start) != 30*time.Minute || e.summary != "Meeting" {
+					t.Errorf("instance %d: %s to %s %q, want %s for 30m", i, e.start, e.end, e.summary, tt.starts[i])
+				}
+			}
+		})
+	}
+}
+
+func TestExpandEventsMoved(t *testing.T) {
+	events := mustParseICS(t, ics(
+		"BEGIN:VEVENT",
+		"UID:standup",
+		"DTSTART:20260302T090000Z",
+		"DURATION:PT15M",
+		"RRULE:FREQ=DAILY;COUNT=3",
+		"SUMMARY:Standup",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:standup",
+		"RECURRENCE-ID:20260303T090000Z",
+		"DTSTART:20260303T110000Z",
+		"DURATION:PT15M",
+		"SUMMARY:Standup (moved)",
+		"END:VEVENT",
+	))
+	got := expandEvents(events, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC))
+	var summaries []string
+	for _, e := range got {
+		summaries = append(summaries, e.start.Format("02 15:04")+" "+e.summary)
+	}
+	want := "02 09:00 Standup, 04 09:00 Standup, 03 11:00 Standup (moved)"
+	if strings.Join(summaries, ", ") != want {
+		t.Errorf("got %s, want %s", strings.Join(summaries, ", "), want)
+	}
+}
+
+func TestEventFromCalendarRecurring(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "work.ics")
+	err := os.WriteFile(path, []byte(ics(
+		"BEGIN:VEVENT",
+		"DTSTART:20250106T090000Z",
+		"DTEND:20250106T091500Z",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+		"SUMMARY:Standup",
+		"END:VEVENT",
+	)), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 3, 7, 12, 0, 0, 0, time.UTC) // a Saturday
+	e, err := eventFromCalendar(path, caldavClient{}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 3, 9, 9, 0, 0, 0, time.UTC); !e.start.Equal(want) {
+		t.Errorf("next standup at %s, want %s", e.start, want)
+	}
+}
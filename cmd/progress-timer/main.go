@@ -0,0 +1,75 @@
+// Command progress-timer here is the countdown of package timer on its
+// own: no config, history, hooks or notifications. It shows what an app
+// embedding the package gets. The full timer is the module root.
+//
+//	go run ./cmd/progress-timer 25m
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/codytheroux96/progress-timer/timer"
+)
+
+type model struct {
+	timer timer.Model
+}
+
+func (m model) Init() tea.Cmd {
+	return m.timer.Init()
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case timer.DoneMsg:
+		return m, tea.Quit
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		case " ", "p":
+			m.timer = m.timer.Toggle()
+			return m, nil
+		}
+	}
+	next, cmd := m.timer.Update(msg)
+	m.timer = next.(timer.Model)
+	return m, cmd
+}
+
+func (m model) View() string {
+	help := "space pauses, q quits"
+	if m.timer.Countdown.Paused() {
+		help = "paused; space resumes, q quits"
+	}
+	return "\n  " + m.timer.View() + "\n\n  " + help + "\n"
+}
+
+// parse reads minutes, or a Go duration such as 1h30m.
+func parse(s string) (time.Duration, error) {
+	if n, err := strconv.Atoi(s); err == nil {
+		return time.Duration(n) * time.Minute, nil
+	}
+	return time.ParseDuration(s)
+}
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: progress-timer DURATION")
+		os.Exit(3)
+	}
+	d, err := parse(os.Args[1])
+	if err != nil || d <= 0 {
+		fmt.Fprintf(os.Stderr, "%q is not minutes or a duration like 1h30m\n", os.Args[1])
+		os.Exit(3)
+	}
+	m := model{timer.New(timer.Options{Duration: d})}
+	if _, err := tea.NewProgram(m).Run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
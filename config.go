@@ -0,0 +1,1000 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type config struct {
+	defaultMinutes   int
+	altScreen        string
+	remote           string
+	icons            string
+	nerdFonts        bool
+	bigDigits        bool
+	rounding         string
+	alwaysHours      bool
+	readoutMode      string
+	viewTemplate     *template.Template
+	position         placement
+	reporter         progressReporter
+	accentColor      string
+	doneColor        string
+	errorColor       string
+	scheme           string
+	bar              barOptions
+	colorProfile     string
+	highContrast     bool
+	reduceMotion     bool
+	soundFile        string
+	soundPlayer      string
+	soundBell        bool
+	soundRepeat      bool
+	escalation       []escalationStep
+	holidayCalendar  string
+	holidays         holidaySet
+	schedules        [10]*schedule
+	quickPicks       [10]string
+	presets          presets
+	templates        templates
+	confirmQuit      bool
+	overtime         bool
+	pauseLimit       int // -1 for no limit
+	pauseTimeLimit   time.Duration
+	breakDuration    time.Duration
+	breakTips        []breakTip
+	adjustStep       time.Duration
+	pomodoro         pomodoro
+	intervals        intervals
+	warmup           time.Duration
+	onTickCmd        string
+	eventHooks       [numHookEvents]string
+	tickHookInterval time.Duration
+	calendarFile     string
+	reviewTime       string // HH:MM, empty for no end-of-day review
+	retention        retention
+	caldav           caldavClient
+	caldavPush       bool
+	notifications    bool
+	rateLimits       rateLimits
+	dryRun           bool // from --dry-run only
+}
+
+type configOption struct {
+	key     string
+	comment string
+	value   string
+	set     func(c *config, v string) error
+}
+
+var configOptions = []configOption{
+	{
+		key:     "default_duration",
+		comment: "Minutes pre-filled on the input screen. Leave empty to start blank.",
+		set: func(c *config, v string) error {
+			if v == "" {
+				c.defaultMinutes = 0
+				return nil
+			}
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				return fmt.Errorf("%q is not a positive number of minutes", v)
+			}
+			c.defaultMinutes = n
+			return nil
+		},
+	},
+	{
+		key:     "presets",
+		comment: "Named durations, e.g. tea=3m, standup=15m, deep=1h30m. Start one by typing its name on\nthe input screen or as the argument (progress-timer tea), or pick it with Ctrl+P.",
+		set: func(c *config, v string) (err error) {
+			c.presets, err = parsePresets(v)
+			return err
+		},
+	},
+	{
+		key:     "alt_screen",
+		comment: "Use the terminal's alternate screen: auto, on or off. Auto disables it in the legacy Windows console.",
+		value:   "auto",
+		set: func(c *config, v string) error {
+			switch v {
+			case "auto", "on", "off":
+				c.altScreen = v
+				return nil
+			}
+			return fmt.Errorf("%q must be auto, on or off", v)
+		},
+	},
+	{
+		key:     "remote",
+		comment: "Low-bandwidth mode for slow connections: auto, on or off. Auto turns it on over SSH.\nIt redraws less often, stops cursors blinking and uses ASCII icons and bar.",
+		value:   "auto",
+		set: func(c *config, v string) error {
+			switch v {
+			case "auto", "on", "off":
+				c.remote = v
+				return nil
+			}
+			return fmt.Errorf("%q must be auto, on or off", v)
+		},
+	},
+	{
+		key:     "icons",
+		comment: "State icons in the header and terminal title: none, emoji, nerd or ascii.",
+		value:   "none",
+		set: func(c *config, v string) error {
+			if _, ok := iconSets[v]; !ok {
+				return fmt.Errorf("%q must be none, emoji, nerd or ascii", v)
+			}
+			c.icons = v
+			return nil
+		},
+	},
+	{
+		key:     "nerd_fonts",
+		comment: "Whether the terminal font is patched with Nerd Font glyphs. The nerd icons fall back to ascii when false.",
+		value:   "false",
+		set:     func(c *config, v string) error { return setBool(&c.nerdFonts, v) },
+	},
+	{
+		key:     "big_digits",
+		comment: "Show the remaining time in large block digits, readable from across the room. d toggles it.",
+		value:   "false",
+		set:     func(c *config, v string) error { return setBool(&c.bigDigits, v) },
+	},
+	{
+		key:     "rounding",
+		comment: "How the time left is rounded to the second: nearest, up (00:00 only once done)\nor down (never more than is left; 00:00 can show for up to a second before the end).",
+		value:   "nearest",
+		set: func(c *config, v string) error {
+			switch v {
+			case "nearest", "up", "down":
+				c.rounding = v
+				return nil
+			}
+			return fmt.Errorf("%q must be nearest, up or down", v)
+		},
+	},
+	{
+		key:     "readout",
+		comment: "What the main readout shows: remaining, elapsed or percent. e cycles through them,\nand the choice is remembered for each --project.",
+		value:   "remaining",
+		set: func(c *config, v string) error {
+			for _, mode := range readoutModes {
+				if v == mode {
+					c.readoutMode = v
+					return nil
+				}
+			}
+			return fmt.Errorf("%q must be remaining, elapsed or percent", v)
+		},
+	},
+	{
+		key:     "clock_format",
+		comment: "adaptive shows hours only when there are some (59:59 after 01:00:00);\nhours always shows them (00:59:59).",
+		value:   "adaptive",
+		set: func(c *config, v string) error {
+			switch v {
+			case "adaptive", "hours":
+				c.alwaysHours = v == "hours"
+				return nil
+			}
+			return fmt.Errorf("%q must be adaptive or hours", v)
+		},
+	},
+	{
+		key:     "confirm_quit",
+		comment: "Ask before abandoning a running timer. Set to false to quit instantly.",
+		value:   "true",
+		set:     func(c *config, v string) error { return setBool(&c.confirmQuit, v) },
+	},
+	{
+		key:     "overtime",
+		comment: "Keep counting past zero with a red +MM:SS overrun instead of stopping.",
+		value:   "false",
+		set:     func(c *config, v string) error { return setBool(&c.overtime, v) },
+	},
+	{
+		key:     "pause_limit",
+		comment: "Pause budget for strict pomodoro discipline: a timer paused more often than this is\nlogged as broken in the history. Leave empty for no limit.",
+		set: func(c *config, v string) error {
+			if v == "" {
+				c.pauseLimit = -1
+				return nil
+			}
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return fmt.Errorf("%q is not a number of pauses", v)
+			}
+			c.pauseLimit = n
+			return nil
+		},
+	},
+	{
+		key:     "pause_time_limit",
+		comment: "Likewise for the total time a timer spends paused, e.g. 5m. Leave empty for no limit.",
+		set: func(c *config, v string) error {
+			if v == "" {
+				c.pauseTimeLimit = 0
+				return nil
+			}
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				return fmt.Errorf("%q is not a positive duration", v)
+			}
+			c.pauseTimeLimit = d
+			return nil
+		},
+	},
+	{
+		key:     "break_duration",
+		comment: "Length of the break started with b from the completion screen.",
+		value:   "5m",
+		set: func(c *config, v string) (err error) {
+			c.breakDuration, err = parseDuration(v)
+			return err
+		},
+	},
+	{
+		key:     "break_suggestions",
+		comment: "Comma-separated things to do on pomodoro breaks and interval rests, one shown per\nbreak in turn. A duration at the end, e.g. Stretch 2m, counts that long before the next\none shows. Empty for none.",
+		value:   "Stretch, Drink some water, Look outside, Walk around",
+		set: func(c *config, v string) (err error) {
+			c.breakTips, err = parseBreakTips(v)
+			return err
+		},
+	},
+	{
+		key:     "adjust_step",
+		comment: "How much + and - add to or take off a running timer, and ↑/↓ or k/j change the duration\non the input screen (five times as much with Shift or K/J).",
+		value:   "1m",
+		set: func(c *config, v string) (err error) {
+			c.adjustStep, err = parseDuration(v)
+			return err
+		},
+	},
+	{
+		key:     "warmup",
+		comment: "Get-ready countdown with a bell each second before a timer starts, e.g. 3s. 0 disables it.",
+		value:   "0s",
+		set: func(c *config, v string) error {
+			d, err := time.ParseDuration(v)
+			if err != nil || d < 0 {
+				return fmt.Errorf("%q must be a duration such as 3s", v)
+			}
+			c.warmup = d
+			return nil
+		},
+	},
+	{
+		key:     "pomodoro",
+		comment: "Cycle used by --pomodoro: work/short break/long break x work phases per long break.",
+		value:   "25/5/15x4",
+		set: func(c *config, v string) (err error) {
+			c.pomodoro, err = parsePomodoro(v)
+			return err
+		},
+	},
+	{
+		key:     "intervals",
+		comment: "Plan used by --intervals: work and rest per round and the number of rounds.",
+		value:   "work=40s rest=20s rounds=8",
+		set: func(c *config, v string) (err error) {
+			c.intervals, err = parseIntervals(v)
+			return err
+		},
+	},
+	{
+		key:     "view_template",
+		comment: "Go text/template for the running screen, e.g. \"{{.Icon}} {{.Remaining}}\\n{{.Bar}} {{.Percent}}\".\nFields: Remaining, Elapsed, Total, Bar, Percent, Label, Icon, Done, Paused, Overtime, Phase, Ends. Empty uses the built-in layout.",
+		set: func(c *config, v string) error {
+			if v == "" {
+				c.viewTemplate = nil
+				return nil
+			}
+			t, err := parseViewTemplate(v)
+			if err != nil {
+				return err
+			}
+			c.viewTemplate = t
+			return nil
+		},
+	},
+	{
+		key:     "position",
+		comment: "Where to place the timer on the alternate screen: center, top, bottom, left, right,\nor a pair like top-left.",
+		value:   "center",
+		set: func(c *config, v string) error {
+			p, err := parsePlacement(v)
+			if err != nil {
+				return err
+			}
+			c.position = p
+			return nil
+		},
+	},
+	{
+		key:     "notifications",
+		comment: "Show a desktop notification when a timer or pomodoro phase ends\n(notify-send on Linux and BSD, Notification Center on macOS, a toast on Windows).",
+		value:   "true",
+		set:     func(c *config, v string) error { return setBool(&c.notifications, v) },
+	},
+	{
+		key:     "delivery.rate_limit",
+		comment: "Least time between two deliveries on one channel, by kind: desktop, calendar and hooks,\ne.g. desktop=30s, hooks=5s. Sooner ones are dropped and logged as skipped.",
+		set: func(c *config, v string) (err error) {
+			c.rateLimits, err = parseRateLimits(v)
+			return err
+		},
+	},
+	{
+		key:     "high_contrast",
+		comment: "Use the terminal's own foreground with bold, underline and reverse video\ninstead of colors, and a bar whose fill and track differ in shape.",
+		value:   "false",
+		set:     func(c *config, v string) error { return setBool(&c.highContrast, v) },
+	},
+	{
+		key:     "reduce_motion",
+		comment: "Stop cursors blinking. Both settings can be toggled at runtime from Ctrl+K.",
+		value:   "false",
+		set:     func(c *config, v string) error { return setBool(&c.reduceMotion, v) },
+	},
+	{
+		key:     "taskbar_progress",
+		comment: "Show progress in the taskbar or tab: auto, osc9 (Windows Terminal, ConEmu, WezTerm),\niterm (iTerm2 badge) or off.",
+		value:   "auto",
+		set: func(c *config, v string) error {
+			switch progressReporter(v) {
+			case reportNone, reportOSC9, reportITerm:
+				c.reporter = progressReporter(v)
+			case "auto":
+				c.reporter = detectProgressReporter()
+			default:
+				return fmt.Errorf("%q must be auto, osc9, iterm or off", v)
+			}
+			return nil
+		},
+	},
+	{
+		key:     "theme",
+		comment: "Color theme: " + strings.Join(schemeNames(), ", ") + ". The t key cycles through them.",
+		value:   "default",
+		set: func(c *config, v string) error {
+			if _, ok := findScheme(v); !ok {
+				return fmt.Errorf("%q must be one of %s", v, strings.Join(schemeNames(), ", "))
+			}
+			c.scheme = v
+			return nil
+		},
+	},
+	{
+		key:     "colors.accent",
+		comment: "Color of the remaining time and percentage, instead of the theme's.",
+		set:     func(c *config, v string) error { return setColor(&c.accentColor, v) },
+	},
+	{
+		key:     "colors.done",
+		comment: "Color of the completion message, instead of the theme's.",
+		set:     func(c *config, v string) error { return setColor(&c.doneColor, v) },
+	},
+	{
+		key:     "colors.error",
+		comment: "Color of input errors, instead of the theme's.",
+		set:     func(c *config, v string) error { return setColor(&c.errorColor, v) },
+	},
+	{
+		key:     "colors.profile",
+		comment: "Color depth: auto, truecolor, 256, 16 or none. Colors above are converted to the\nnearest the terminal can show; with none, states are marked by bold and reverse video.",
+		value:   "auto",
+		set: func(c *config, v string) error {
+			switch v {
+			case "auto", "truecolor", "256", "16", "none":
+				c.colorProfile = v
+				return nil
+			}
+			return fmt.Errorf("%q must be auto, truecolor, 256, 16 or none", v)
+		},
+	},
+	{
+		key:     "bar.fill",
+		comment: "How the bar is colored: theme (as the theme draws it), solid or gradient.",
+		value:   "theme",
+		set: func(c *config, v string) error {
+			switch v {
+			case "theme", "solid", "gradient":
+				c.bar.fill = v
+				return nil
+			}
+			return fmt.Errorf("%q must be theme, solid or gradient", v)
+		},
+	},
+	{
+		key:     "bar.full_char",
+		comment: "Character for the filled part of the bar.",
+		value:   "█",
+		set:     func(c *config, v string) error { return setBarChar(&c.bar.full, v) },
+	},
+	{
+		key:     "bar.empty_char",
+		comment: "Character for the empty part of the bar; write \" \" for a space.",
+		value:   "░",
+		set:     func(c *config, v string) error { return setBarChar(&c.bar.empty, v) },
+	},
+	{
+		key:     "bar.direction",
+		comment: "up fills the bar as time passes; down starts full and shrinks with the time left.",
+		value:   "up",
+		set: func(c *config, v string) error {
+			switch v {
+			case "up", "down":
+				c.bar.countDown = v == "down"
+				return nil
+			}
+			return fmt.Errorf("%q must be up or down", v)
+		},
+	},
+	{
+		key:     "bar.percent",
+		comment: "Where the percentage goes: right (end of the line), inline (next to the bar) or hidden.",
+		value:   "right",
+		set: func(c *config, v string) error {
+			switch v {
+			case "right", "inline", "hidden":
+				c.bar.percent = v
+				return nil
+			}
+			return fmt.Errorf("%q must be right, inline or hidden", v)
+		},
+	},
+	{
+		key:     "history.keep",
+		comment: "How long to keep each timer in the history, e.g. 1y, 12w or 90d. Older timers are\nfolded into daily totals, which stats still count, at launch or with\n`progress-timer prune`. Empty keeps everything.",
+		set: func(c *config, v string) (err error) {
+			c.retention.raw, err = parseRetention(v)
+			return err
+		},
+	},
+	{
+		key:     "history.keep_daily",
+		comment: "How long to keep the daily totals of pruned timers. Empty keeps them forever.",
+		set: func(c *config, v string) (err error) {
+			c.retention.daily, err = parseRetention(v)
+			return err
+		},
+	},
+	{
+		key:     "review.time",
+		comment: "When to open the end-of-day review, e.g. 17:30: a summary of the day's timers and\na line of reflection for the journal. A later launch opens it if the time passed\nwhile the timer wasn't running; once a day. Empty for none.\nOpen it any time with `progress-timer review`.",
+		set: func(c *config, v string) error {
+			if v != "" {
+				if _, err := time.Parse("15:04", v); err != nil {
+					return fmt.Errorf("%q is not a time like 17:30", v)
+				}
+			}
+			c.reviewTime = v
+			return nil
+		},
+	},
+	{
+		key:     "calendar.file",
+		comment: "iCalendar (.ics) file used by --from-calendar.",
+		set: func(c *config, v string) error {
+			c.calendarFile = v
+			return nil
+		},
+	},
+	{
+		key:     "caldav.url",
+		comment: "CalDAV calendar collection, used by --from-calendar when calendar.file is empty.",
+		set: func(c *config, v string) error {
+			if v != "" && !isCalDAVURL(v) {
+				return fmt.Errorf("%q must be an http(s) URL", v)
+			}
+			c.caldav.url = v
+			return nil
+		},
+	},
+	{
+		key:     "caldav.username",
+		comment: "CalDAV credentials. Prefer setting the password through " + envName("caldav.password") + ".",
+		set: func(c *config, v string) error {
+			c.caldav.username = v
+			return nil
+		},
+	},
+	{
+		key: "caldav.password",
+		set: func(c *config, v string) error {
+			c.caldav.password = v
+			return nil
+		},
+	},
+	{
+		key:     "caldav.push",
+		comment: "Add each completed timer to the CalDAV calendar as an event.",
+		value:   "false",
+		set:     func(c *config, v string) error { return setBool(&c.caldavPush, v) },
+	},
+	{
+		key:     "hooks.on_tick",
+		comment: "Command run while a timer is running, with the remaining seconds appended as an argument.",
+		set: func(c *config, v string) error {
+			c.onTickCmd = v
+			return nil
+		},
+	},
+	{
+		key:     "hooks.tick_interval",
+		comment: "Minimum time between on_tick runs.",
+		value:   "10s",
+		set: func(c *config, v string) error {
+			d, err := time.ParseDuration(v)
+			if err != nil || d < minTickHookInterval {
+				return fmt.Errorf("%q must be a duration of at least %s", v, minTickHookInterval)
+			}
+			c.tickHookInterval = d
+			return nil
+		},
+	},
+	eventHookOption(eventStart, "Commands run on timer events, given TIMER_EVENT, TIMER_LABEL, TIMER_DURATION and\nTIMER_REMAINING (seconds) and TIMER_PHASE in the environment. on_start runs once a\ntimer starts counting, on_phase when a pomodoro or interval plan moves on instead."),
+	eventHookOption(eventPause, ""),
+	eventHookOption(eventResume, ""),
+	eventHookOption(eventComplete, ""),
+	eventHookOption(eventPhase, ""),
+	eventHookOption(eventEscalate, "on_escalate runs for alert.escalation steps with hook, e.g. to send a push notification."),
+	{
+		key:     "sound.file",
+		comment: "Sound file to play when a timer completes. Relative names are looked up in the sounds data directory.",
+		set: func(c *config, v string) (err error) {
+			if v == "" {
+				c.soundFile = ""
+				return nil
+			}
+			c.soundFile, err = checkSoundFile(v)
+			return err
+		},
+	},
+	{
+		key:     "sound.player",
+		comment: "Command that plays sound.file, which is appended to it. Leave empty to use afplay on\nmacOS, SoundPlayer on Windows (wav only), or the first of paplay, pw-play, aplay, ffplay\nor mpv found elsewhere.",
+		set:     func(c *config, v string) error { c.soundPlayer = v; return nil },
+	},
+	{
+		key:     "sound.bell",
+		comment: "Ring the terminal bell when a timer or pomodoro phase ends.",
+		value:   "true",
+		set:     func(c *config, v string) error { return setBool(&c.soundBell, v) },
+	},
+	{
+		key:     "sound.repeat",
+		comment: "Repeat the bell and sound every few seconds until a key is pressed.",
+		value:   "false",
+		set:     func(c *config, v string) error { return setBool(&c.soundRepeat, v) },
+	},
+	{
+		key:     "alert.escalation",
+		comment: "Steps taken while an alert goes unacknowledged, as <after> <actions>, e.g.\n1m sound=loud.wav, 3m notify+hook. Actions are bell, sound (sound.file), sound=FILE,\nwhich later repeats play too, notify (a desktop notification) and hook (hooks.on_escalate).",
+		set: func(c *config, v string) (err error) {
+			c.escalation, err = parseEscalation(v)
+			return err
+		},
+	},
+}
+
+// checkSoundFile resolves a sound file setting and checks it can be read.
+func checkSoundFile(v string) (string, error) {
+	f, err := os.Open(resolveSound(v))
+	if err != nil {
+		return "", fmt.Errorf("%s is not readable", v)
+	}
+	defer f.Close()
+	if fi, err := f.Stat(); err != nil || fi.IsDir() {
+		return "", fmt.Errorf("%s is not a regular file", v)
+	}
+	return resolveSound(v), nil
+}
+
+func init() {
+	for i := 1; i <= 9; i++ {
+		o := configOption{
+			key: fmt.Sprintf("quick.%d", i),
+			set: func(c *config, v string) error {
+				if v != "" {
+					if _, err := parseDuration(v); err != nil {
+						return fmt.Errorf("%q is not a duration", v)
+					}
+				}
+				c.quickPicks[i] = v
+				return nil
+			},
+		}
+		if i == 1 {
+			o.comment = "Durations started by pressing 1-9 on an empty input screen, e.g. 1 = 5m, 3 = 25m.\nStart typing with 0 (e.g. 05) to enter a custom duration beginning with that digit."
+		}
+		configOptions = append(configOptions, o)
+	}
+	for i := 1; i <= 9; i++ {
+		o := configOption{
+			key: fmt.Sprintf("schedule.%d", i),
+			set: func(c *config, v string) error {
+				c.schedules[i] = nil
+				if v == "" {
+					return nil
+				}
+				sc, err := parseSchedule(v)
+				if err != nil {
+					return err
+				}
+				c.schedules[i] = &sc
+				return nil
+			},
+		}
+		if i == 1 {
+			o.comment = "Recurring timers as <days> <HH:MM> [skip|late] <duration> [label], where days is\ndaily, weekdays, workdays (weekdays that are not holidays), weekends or a list such\nas mon,wed,fri. E.g. 1 = weekdays 09:00 50m Focus.\nA trigger missed while the machine slept is skipped, or started on wake-up with late.\nList the upcoming ones with `progress-timer schedule`; run them with `schedule run`."
+		}
+		configOptions = append(configOptions, o)
+	}
+	for i := 1; i <= 9; i++ {
+		o := configOption{
+			key: fmt.Sprintf("template.%d", i),
+			set: func(c *config, v string) error {
+				c.templates = slices.DeleteFunc(c.templates, func(t timerTemplate) bool { return t.slot == i })
+				if v == "" {
+					return nil
+				}
+				t, err := parseTemplate(v)
+				if err != nil {
+					return err
+				}
+				t.slot = i
+				c.templates = append(c.templates, t)
+				slices.SortFunc(c.templates, func(a, b timerTemplate) int { return a.slot - b.slot })
+				return nil
+			},
+		}
+		if i == 1 {
+			o.comment = "Chains of timers started by typing their name, with {blanks} asked for at start, e.g.\n1 = meeting: Intro 5m, {n}x Item {i} {m}m, Wrap-up 5m. A step is an optional count\n(3x or {n}x), a label and a duration; {i} numbers the steps of a count."
+		}
+		configOptions = append(configOptions, o)
+	}
+	configOptions = append(configOptions,
+		configOption{
+			key:     "schedule.holidays",
+			comment: "Dates that are not workdays, e.g. 2026-12-24, 2026-12-25.",
+			set: func(c *config, v string) (err error) {
+				c.holidays, err = parseHolidays(v)
+				return err
+			},
+		},
+		configOption{
+			key:     "schedule.holiday_calendar",
+			comment: "An .ics file or http(s) feed whose all-day events are holidays too.",
+			set: func(c *config, v string) error {
+				c.holidayCalendar = v
+				return nil
+			},
+		},
+	)
+}
+
+func setBool(dst *bool, v string) error {
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fmt.Errorf("%q must be true or false", v)
+	}
+	*dst = b
+	return nil
+}
+
+// setBarChar takes a single character one cell wide.
+func setBarChar(dst *rune, v string) error {
+	r := []rune(v)
+	if len(r) != 1 || lipgloss.Width(v) != 1 {
+		return fmt.Errorf("%q must be a single character one cell wide", v)
+	}
+	*dst = r[0]
+	return nil
+}
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+func setColor(dst *string, v string) error {
+	if v != "" && !hexColor.MatchString(v) {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > 255 {
+			return fmt.Errorf("%q is not a color; use #RRGGBB, #RGB or an ANSI code 0-255", v)
+		}
+	}
+	*dst = v
+	return nil
+}
+
+// programOptions are the Bubble Tea options the config asks for.
+func (c config) programOptions() []tea.ProgramOption {
+	var opts []tea.ProgramOption
+	if c.useAltScreen() {
+		opts = append(opts, tea.WithAltScreen())
+	}
+	if c.isRemote() {
+		opts = append(opts, tea.WithFPS(remoteFPS))
+	}
+	return opts
+}
+
+func (c config) useAltScreen() bool {
+	switch c.altScreen {
+	case "on":
+		return true
+	case "off":
+		return false
+	}
+	return altScreenSupported()
+}
+
+func lookupOption(key string) (configOption, bool) {
+	for _, o := range configOptions {
+		if o.key == key {
+			return o, true
+		}
+	}
+	return configOption{}, false
+}
+
+func defaultConfig() config {
+	var c config
+	for _, o := range configOptions {
+		if err := o.set(&c, o.value); err != nil {
+			panic("invalid default for " + o.key + ": " + err.Error())
+		}
+	}
+	return c
+}
+
+const envPrefix = "PROGRESS_TIMER_"
+
+// envName maps a config key such as colors.accent to PROGRESS_TIMER_COLORS_ACCENT.
+func envName(key string) string {
+	return envPrefix + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+}
+
+// applyEnv lets PROGRESS_TIMER_* variables override values from the file.
+func applyEnv(c *config) []error {
+	var errs []error
+	for _, o := range configOptions {
+		v, ok := os.LookupEnv(envName(o.key))
+		if !ok {
+			continue
+		}
+		if err := o.set(c, strings.TrimSpace(v)); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", envName(o.key), err))
+		}
+	}
+	return errs
+}
+
+type configEntry struct {
+	key   string
+	value string
+	line  int
+}
+
+type configError struct {
+	path string
+	line int
+	msg  string
+}
+
+func (e configError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.path, e.line, e.msg)
+}
+
+// parseConfig reads key = value lines grouped under optional [section]
+// headers. Values may be double-quoted; unquoted values run to the end of
+// the line so that colors like #FF0000 need no quoting.
+func parseConfig(path string, r io.Reader) ([]configEntry, []error) {
+	var (
+		entries []configEntry
+		errs    []error
+		section string
+	)
+
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				errs = append(errs, configError{path, n, "unterminated section header"})
+				continue
+			}
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			errs = append(errs, configError{path, n, fmt.Sprintf("expected key = value, got %q", line)})
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if key == "" {
+			errs = append(errs, configError{path, n, "missing key before ="})
+			continue
+		}
+		if strings.HasPrefix(value, `"`) {
+			uq, err := strconv.Unquote(value)
+			if err != nil {
+				errs = append(errs, configError{path, n, fmt.Sprintf("malformed quoted value %s", value)})
+				continue
+			}
+			value = uq
+		}
+		if section != "" {
+			key = section + "." + key
+		}
+		entries = append(entries, configEntry{key: key, value: value, line: n})
+	}
+	if err := sc.Err(); err != nil {
+		errs = append(errs, fmt.Errorf("%s: %w", path, err))
+	}
+	return entries, errs
+}
+
+func applyConfig(c *config, path string, entries []configEntry) []error {
+	var errs []error
+	for _, e := range entries {
+		o, ok := lookupOption(e.key)
+		if !ok {
+			errs = append(errs, configError{path, e.line, fmt.Sprintf("unknown key %q", e.key)})
+			continue
+		}
+		if err := o.set(c, e.value); err != nil {
+			errs = append(errs, configError{path, e.line, fmt.Sprintf("%s: %v", e.key, err)})
+		}
+	}
+	return errs
+}
+
+// loadConfig falls back to the defaults when path does not exist.
+// Overlays, such as a project's settings, are applied on top and must
+// exist. Environment overrides are applied either way.
+func loadConfig(path string, overlays ...string) (config, error) {
+	c := defaultConfig()
+
+	f, err := os.Open(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return c, err
+	}
+
+	var errs []error
+	if f != nil {
+		defer f.Close()
+		entries, perrs := parseConfig(path, f)
+		errs = append(perrs, applyConfig(&c, path, entries)...)
+	}
+	for _, o := range overlays {
+		of, err := os.Open(o)
+		if err != nil {
+			return c, err
+		}
+		defer of.Close()
+		entries, perrs := parseConfig(o, of)
+		errs = append(errs, perrs...)
+		errs = append(errs, applyConfig(&c, o, entries)...)
+	}
+	errs = append(errs, applyEnv(&c)...)
+	return c, errors.Join(errs...)
+}
+
+func writeDefaultConfig(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("# progress-timer configuration\n")
+	b.WriteString("#\n")
+	b.WriteString("# Values may be double-quoted. Unquoted values run to the end of the line.\n")
+	b.WriteString("# Every key can be overridden from the environment, e.g. colors.accent\n")
+	b.WriteString("# by " + envName("colors.accent") + ".\n")
+
+	section := ""
+	for _, o := range configOptions {
+		sec, name, ok := strings.Cut(o.key, ".")
+		if !ok {
+			sec, name = "", o.key
+		}
+		if sec != section {
+			fmt.Fprintf(&b, "\n[%s]\n", sec)
+			section = sec
+		}
+		if o.comment != "" {
+			b.WriteString("\n")
+			for _, line := range strings.Split(o.comment, "\n") {
+				fmt.Fprintf(&b, "# %s\n", line)
+			}
+		}
+		if o.value == "" {
+			fmt.Fprintf(&b, "# %s =\n", name)
+		} else {
+			fmt.Fprintf(&b, "%s = %s\n", name, o.value)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func runConfigCommand(args []string) int {
+	if len(args) == 0 {
+		return usage("config")
+	}
+
+	path := configFile()
+	if len(args) > 1 {
+		path = args[1]
+	}
+
+	switch args[0] {
+	case "init":
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		defer f.Close()
+		if err := writeDefaultConfig(f); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		fmt.Printf("Wrote %s\n", path)
+		return 0
+
+	case "check":
+		f, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		defer f.Close()
+
+		c := defaultConfig()
+		entries, errs := parseConfig(path, f)
+		errs = append(errs, applyConfig(&c, path, entries)...)
+		sort.SliceStable(errs, func(i, j int) bool {
+			ei, _ := errs[i].(configError)
+			ej, _ := errs[j].(configError)
+			return ei.line < ej.line
+		})
+		errs = append(errs, applyEnv(&c)...)
+		for _, err := range errs {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		if len(errs) > 0 {
+			return exitInvalid
+		}
+		fmt.Printf("%s: ok\n", path)
+		return 0
+
+	default:
+		fmt.Fprintf(os.Stderr, "unknown config command %q\n", args[0])
+		return exitInvalid
+	}
+}
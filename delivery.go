@@ -0,0 +1,238 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Desktop notifications, calendar pushes and hooks run in the background.
+// When one fails the timer keeps going; the failure is logged and a
+// warning stays on screen, with details behind the ! key. Hooks are left
+// running, so one that starts but later exits with an error is logged
+// without the warning. With --dry-run
+// nothing is sent and the log records what would have been.
+//
+// Everything goes out through a dispatcher, which drops repeats and
+// deliveries over a channel's rate limit before they are sent.
+
+type deliveryFailure struct {
+	at      time.Time
+	channel string
+	err     string
+}
+
+type deliveryErrMsg deliveryFailure
+
+func deliveryLogFile() string {
+	return filepath.Join(stateDir(), "delivery.log")
+}
+
+// deliveryFailed logs a failed delivery and reports it to the model. It
+// runs inside the delivering command, off the UI goroutine.
+func deliveryFailed(channel string, err error) deliveryErrMsg {
+	msg := deliveryErrMsg{at: time.Now(), channel: channel, err: err.Error()}
+	_ = logDelivery(msg.at, channel, "failed", msg.err)
+	return msg
+}
+
+// deliver sends on one channel with a deadline, logging the result. A
+// channel that doesn't answer in time is reported as failed and left to
+// finish in the background, so it can't hold up the alarm or quitting.
+// Channels are batched, so they run side by side.
+func deliver(channel string, timeout time.Duration, send func() error) tea.Cmd {
+	return func() tea.Msg {
+		done := make(chan error, 1)
+		go func() { done <- send() }()
+		select {
+		case err := <-done:
+			if err != nil {
+				return deliveryFailed(channel, err)
+			}
+			_ = logDelivery(time.Now(), channel, "sent", "")
+			return nil
+		case <-time.After(timeout):
+			return deliveryFailed(channel, fmt.Errorf("no answer after %s", timeout))
+		}
+	}
+}
+
+// rateGroups are the names delivery.rate_limit sets limits for, by the
+// prefix of the channels they cover.
+var rateGroups = map[string]string{
+	"desktop":  "Desktop notification",
+	"calendar": "Calendar sync",
+	"hooks":    "Hook",
+}
+
+// rateLimits maps a rate group to the least time between two deliveries
+// on one of its channels.
+type rateLimits map[string]time.Duration
+
+func parseRateLimits(v string) (rateLimits, error) {
+	limits := rateLimits{}
+	for _, f := range strings.Split(v, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		name, gap, _ := strings.Cut(f, "=")
+		name = strings.TrimSpace(name)
+		if _, ok := rateGroups[name]; !ok {
+			return nil, fmt.Errorf("%q should be desktop, calendar or hooks", name)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(gap))
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("%s: %q is not a duration", name, gap)
+		}
+		limits[name] = d
+	}
+	return limits, nil
+}
+
+// limit is the rate limit of channel. The tick hook keeps its own
+// interval instead.
+func (l rateLimits) limit(channel string) time.Duration {
+	if channel == tickHookChannel {
+		return 0
+	}
+	for name, prefix := range rateGroups {
+		if strings.HasPrefix(channel, prefix) {
+			return l[name]
+		}
+	}
+	return 0
+}
+
+// dispatcher is the one way out for deliveries. It drops one whose key
+// was already delivered on its channel, such as a second alert for the
+// same completion, and one that comes sooner after the last on its
+// channel than the channel's rate limit allows. Dropped deliveries are
+// logged as skipped. The timers of a session share one, so the limits
+// hold across them. A nil dispatcher lets everything through.
+type dispatcher struct {
+	mu     sync.Mutex
+	limits rateLimits
+	now    func() time.Time
+	last   map[string]time.Time // by channel
+	sent   map[string]bool      // by channel and key
+}
+
+func newDispatcher(limits rateLimits) *dispatcher {
+	return &dispatcher{limits: limits, now: time.Now, last: map[string]time.Time{}, sent: map[string]bool{}}
+}
+
+// hold decides on a delivery as it is made, returning the command that
+// logs it as skipped if it is dropped. An empty key is never a repeat.
+func (d *dispatcher) hold(channel, key string) tea.Cmd {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := d.now()
+	why := ""
+	switch last, ok := d.last[channel]; {
+	case key != "" && d.sent[channel+"\x00"+key]:
+		why = "already sent " + key
+	case ok && now.Sub(last) < d.limits.limit(channel):
+		why = "rate limited: " + formatDuration(now.Sub(last)) + " after the last"
+	}
+	if why != "" {
+		return func() tea.Msg {
+			_ = logDelivery(now, channel, "skipped", why)
+			return nil
+		}
+	}
+	d.last[channel] = now
+	if key != "" {
+		d.sent[channel+"\x00"+key] = true
+	}
+	return nil
+}
+
+// deliver is deliver once the dispatcher lets it through.
+func (d *dispatcher) deliver(channel, key string, timeout time.Duration, send func() error) tea.Cmd {
+	if skip := d.hold(channel, key); skip != nil {
+		return skip
+	}
+	return deliver(channel, timeout, send)
+}
+
+// dryRun is dryRun once the dispatcher lets it through, so a dry run
+// shows what the limits would drop.
+func (d *dispatcher) dryRun(channel, key, detail string) tea.Cmd {
+	if skip := d.hold(channel, key); skip != nil {
+		return skip
+	}
+	return dryRun(channel, detail)
+}
+
+// runHook is runHook once the dispatcher lets it through.
+func (d *dispatcher) runHook(channel, key string, c *exec.Cmd) tea.Cmd {
+	if skip := d.hold(channel, key); skip != nil {
+		return skip
+	}
+	return runHook(channel, c)
+}
+
+// dryRun logs what a delivery would have sent.
+func dryRun(channel, detail string) tea.Cmd {
+	return func() tea.Msg {
+		_ = logDelivery(time.Now(), channel, "dry-run", detail)
+		return nil
+	}
+}
+
+func logDelivery(at time.Time, channel, result, detail string) error {
+	if err := os.MkdirAll(stateDir(), 0o755); err != nil {
+		return err
+	}
+	log, err := os.OpenFile(deliveryLogFile(), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	defer log.Close()
+	_, err = fmt.Fprintf(log, "%s\t%s\t%s\t%s\n", at.Format(time.RFC3339), channel, result,
+		strings.Join(strings.Fields(detail), " "))
+	return err
+}
+
+// failureBadge reads e.g. "! 2 deliveries failed (! for details)".
+func (m model) failureBadge() string {
+	if len(m.failures) == 0 {
+		return ""
+	}
+	icon := m.icons.warning
+	if icon == "" {
+		icon = "!"
+	}
+	what := "delivery"
+	if len(m.failures) > 1 {
+		what = "deliveries"
+	}
+	hint := "! for details"
+	if m.showFailures {
+		hint = "! to hide"
+	}
+	return errorStyle.Render(fmt.Sprintf("%s %d %s failed", icon, len(m.failures), what)) + " (" + hint + ")"
+}
+
+// failuresView lists the failures newest first.
+func (m model) failuresView() string {
+	var b strings.Builder
+	for i := len(m.failures) - 1; i >= 0; i-- {
+		f := m.failures[i]
+		b.WriteString(placeText(fmt.Sprintf("%s %s: %s", f.at.Format("15:04"), f.channel, f.err), m.rowWidth()))
+		b.WriteString("\n")
+	}
+	b.WriteString(lipgloss.NewStyle().Faint(true).Render("Logged to " + deliveryLogFile()))
+	return b.String()
+}
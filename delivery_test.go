@@ -0,0 +1,105 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRateLimits(t *testing.T) {
+	l, err := parseRateLimits("desktop=30s, hooks = 5s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		channel string
+		want    time.Duration
+	}{
+		{"Desktop notification", 30 * time.Second},
+		{"Hook complete", 5 * time.Second},
+		{tickHookChannel, 0},
+		{"Calendar sync", 0},
+	}
+	for _, tt := range tests {
+		if got := l.limit(tt.channel); got != tt.want {
+			t.Errorf("limit(%q) = %s, want %s", tt.channel, got, tt.want)
+		}
+	}
+	for _, bad := range []string{"push=1m", "desktop=soon", "desktop=-1s"} {
+		if _, err := parseRateLimits(bad); err == nil {
+			t.Errorf("%q was accepted", bad)
+		}
+	}
+}
+
+// countingNotifier counts the notifications it is asked to show.
+type countingNotifier struct{ n *int }
+
+func (c countingNotifier) notify(title, body string) error {
+	*c.n++
+	return nil
+}
+
+func TestDispatcher(t *testing.T) {
+	isolate(t)
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	d := newDispatcher(rateLimits{"desktop": time.Minute})
+	d.now = func() time.Time { return now }
+	sent := 0
+	send := func(channel, key string) {
+		runHeadlessCmd(d.deliver(channel, key, time.Second, func() error { sent++; return nil }))
+	}
+
+	send("Desktop notification", "complete of timer 1")
+	send("Desktop notification", "complete of timer 1") // a repeat
+	if sent != 1 {
+		t.Fatalf("a repeated key was sent %d times", sent)
+	}
+	now = now.Add(30 * time.Second)
+	send("Desktop notification", "complete of timer 2") // over the limit
+	send("Hook complete", "complete of timer 2")        // another channel
+	send("Hook complete", "complete of timer 1")        // same key, other channel
+	if sent != 3 {
+		t.Errorf("sent %d, want 3: the hooks but not the rate-limited notification", sent)
+	}
+	now = now.Add(time.Minute)
+	send("Desktop notification", "complete of timer 2")
+	if sent != 4 {
+		t.Errorf("sent %d after the limit passed, want 4", sent)
+	}
+
+	log, err := os.ReadFile(deliveryLogFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(string(log), "\tskipped\t"); got != 2 {
+		t.Errorf("%d skipped deliveries logged, want 2:\n%s", got, log)
+	}
+	if !strings.Contains(string(log), "rate limited") || !strings.Contains(string(log), "already sent") {
+		t.Errorf("log doesn't say why:\n%s", log)
+	}
+
+	var none *dispatcher
+	if none.hold("Desktop notification", "x") != nil || none.hold("Desktop notification", "x") != nil {
+		t.Error("a nil dispatcher dropped a delivery")
+	}
+}
+
+func TestCompletionNotifiesOnce(t *testing.T) {
+	m, clk := startedModel(t, "1")
+	n := 0
+	m.notifier = countingNotifier{&n}
+	for range 2 {
+		runHeadlessCmd(m.desktopNotify(eventComplete.String(), m.completionMessage()))
+	}
+	if n != 1 {
+		t.Errorf("notified %d times for one completion, want 1", n)
+	}
+	clk.advance(time.Minute)
+	m = m.begin(time.Minute)
+	runHeadlessCmd(m.desktopNotify(eventComplete.String(), m.completionMessage()))
+	if n != 2 {
+		t.Errorf("the next timer's completion: notified %d times in all, want 2", n)
+	}
+}
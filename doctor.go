@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/term"
+	"github.com/muesli/termenv"
+)
+
+const doctorSeconds = 5
+
+// timingReport is what a short run of the countdown engine measured.
+type timingReport struct {
+	ticks     int
+	meanJit   time.Duration // mean distance of a tick from one second
+	worstJit  time.Duration
+	finish    time.Duration // when the timer completed relative to its deadline
+	clockSkew time.Duration // wall clock against the monotonic clock
+}
+
+// measureTiming runs a timer of the given length through the model the
+// way the TUI does: one-second ticks rescheduled after each other, and a
+// last one timed to land on the deadline.
+func measureTiming(d time.Duration) timingReport {
+	var r timingReport
+	start := time.Now()
+	m := initialModel(defaultConfig()).begin(d)
+	last := start
+	var total time.Duration
+	for !m.done {
+		wait := time.Second
+		left, final := m.finalSecond()
+		if final {
+			wait = left
+		}
+		now := <-time.After(wait)
+		if !final {
+			jit := now.Sub(last) - time.Second
+			jit = max(jit, -jit)
+			total += jit
+			r.worstJit = max(r.worstJit, jit)
+			r.ticks++
+		}
+		last = now
+		m, _ = m.tick(m.clock.Now())
+	}
+	r.finish = m.clock.Now().Sub(m.countdown.Deadline)
+	r.meanJit = total / time.Duration(max(r.ticks, 1))
+	r.clockSkew = m.clock.Now().Sub(m.countdown.Started) - time.Since(start)
+	return r
+}
+
+// check is one line of the doctor's report.
+type check struct {
+	status string // ok, warn or FAIL
+	name   string
+	detail string
+}
+
+func (c check) String() string {
+	return fmt.Sprintf("%-4s  %-13s %s", c.status, c.name, c.detail)
+}
+
+func profileName(p termenv.Profile) string {
+	switch p {
+	case termenv.TrueColor:
+		return "true color"
+	case termenv.ANSI256:
+		return "256 colors"
+	case termenv.ANSI:
+		return "16 colors"
+	}
+	return "no color"
+}
+
+// measureWidth prints s and asks the terminal where the cursor ended up,
+// which is how wide the terminal really draws it. It reports false when
+// stdin isn't a terminal or the terminal doesn't answer.
+func measureWidth(s string) (int, bool) {
+	fd := os.Stdin.Fd()
+	if !term.IsTerminal(fd) || !term.IsTerminal(os.Stdout.Fd()) {
+		return 0, false
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return 0, false
+	}
+	defer term.Restore(fd, state)
+	fmt.Print("\r" + s + "\x1b[6n")
+	defer fmt.Print("\r\x1b[K")
+
+	reply := make(chan string, 1)
+	go func() {
+		r, _ := bufio.NewReader(os.Stdin).ReadString('R')
+		reply <- r
+	}()
+	select {
+	case r := <-reply:
+		var row, col int
+		if _, err := fmt.Sscanf(r[strings.LastIndex(r, "\x1b["):], "\x1b[%d;%dR", &row, &col); err != nil {
+			return 0, false
+		}
+		return col - 1, true
+	case <-time.After(500 * time.Millisecond):
+		return 0, false
+	}
+}
+
+// environmentChecks looks at what usually explains rendering and sound
+// reports: the config, the terminal, and the notification and audio
+// commands.
+func environmentChecks() []check {
+	var checks []check
+	path := configFile()
+	cfg, err := loadConfig(path)
+	switch {
+	case err != nil:
+		checks = append(checks, check{"FAIL", "config", err.Error()})
+	case fileExists(path):
+		checks = append(checks, check{"ok", "config", path})
+	default:
+		checks = append(checks, check{"ok", "config", "no file, using defaults (progress-timer config init " + path + ")"})
+	}
+
+	tty := term.IsTerminal(os.Stdout.Fd())
+	if tty {
+		checks = append(checks, check{"ok", "terminal", "TERM=" + os.Getenv("TERM")})
+	} else {
+		checks = append(checks, check{"warn", "terminal", "output is not a terminal, so the TUI can't draw"})
+	}
+	profile := colorProfile(cfg.colorProfile)
+	if profile == termenv.Ascii {
+		checks = append(checks, check{"warn", "colors", "none; states are shown in bold and reverse video (NO_COLOR or TERM=dumb?)"})
+	} else {
+		checks = append(checks, check{"ok", "colors", profileName(profile)})
+	}
+	if cfg.useAltScreen() {
+		checks = append(checks, check{"ok", "alt screen", "on"})
+	} else {
+		checks = append(checks, check{"warn", "alt screen", "off; the timer draws inline (alt_screen = on forces it)"})
+	}
+	if cfg.isRemote() {
+		checks = append(checks, check{"ok", "remote", "on: ASCII bar and icons, no notifications or sound"})
+	}
+	locale := os.Getenv("LC_ALL") + os.Getenv("LC_CTYPE") + os.Getenv("LANG")
+	if !strings.Contains(strings.ToUpper(strings.ReplaceAll(locale, "-", "")), "UTF8") && os.Getenv("WT_SESSION") == "" {
+		checks = append(checks, check{"warn", "locale", "not UTF-8; block characters may show as ? (remote = on uses ASCII)"})
+	}
+	for _, probe := range []struct{ name, s, fix string }{
+		{"bar width", "█", "remote = on draws an ASCII bar"},
+		{"emoji width", iconSets["emoji"].running, "try icons = ascii"},
+		{"dot width", "·", "the summary and phase lines may wrap"},
+	} {
+		want := lipgloss.Width(probe.s)
+		got, ok := measureWidth(probe.s)
+		switch {
+		case !ok:
+			if tty {
+				checks = append(checks, check{"warn", probe.name, "the terminal didn't report its cursor position"})
+			}
+		case got != want:
+			checks = append(checks, check{"warn", probe.name, fmt.Sprintf("%q is %d cells wide, expected %d; %s", probe.s, got, want, probe.fix)})
+		default:
+			checks = append(checks, check{"ok", probe.name, fmt.Sprintf("%q is %d cells", probe.s, got)})
+		}
+	}
+
+	switch {
+	case !cfg.notifications:
+		checks = append(checks, check{"ok", "notifications", "off"})
+	case cfg.notifier() == nil:
+		checks = append(checks, check{"ok", "notifications", "off over SSH"})
+	default:
+		if p, err := exec.LookPath(notifyCommand); err != nil {
+			checks = append(checks, check{"FAIL", "notifications", notifyCommand + " not found; install it or set notifications = false"})
+		} else {
+			checks = append(checks, check{"ok", "notifications", p})
+		}
+	}
+
+	p := cfg.player()
+	switch {
+	case cfg.soundFile == "" && cfg.soundBell:
+		checks = append(checks, check{"ok", "sound", "terminal bell only; set sound.file for a sound"})
+	case cfg.soundFile == "":
+		checks = append(checks, check{"ok", "sound", "off"})
+	case !fileExists(cfg.soundFile):
+		checks = append(checks, check{"FAIL", "sound", cfg.soundFile + " does not exist"})
+	case p == nil && cfg.isRemote():
+		checks = append(checks, check{"ok", "sound", "off over SSH"})
+	case p == nil:
+		checks = append(checks, check{"FAIL", "sound", "no player found; install one of paplay, pw-play, aplay, ffplay or mpv, or set sound.player"})
+	case p == mediaPlayer{} && !strings.EqualFold(filepath.Ext(cfg.soundFile), ".wav"):
+		checks = append(checks, check{"FAIL", "sound", "Windows plays only .wav files; convert " + cfg.soundFile + " or set sound.player"})
+	default:
+		checks = append(checks, check{"ok", "sound", playerName(p) + " plays " + cfg.soundFile})
+	}
+	return checks
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func playerName(p player) string {
+	switch p := p.(type) {
+	case execPlayer:
+		return p[0]
+	case commandPlayer:
+		return string(p)
+	}
+	return "Windows Media.SoundPlayer"
+}
+
+func runDoctorCommand(args []string) int {
+	seconds := doctorSeconds
+	if len(args) > 1 {
+		return usage("doctor")
+	}
+	if len(args) == 1 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			fmt.Fprintf(os.Stderr, "%q is not a number of seconds\n", args[0])
+			return exitInvalid
+		}
+		seconds = n
+	}
+
+	defer enableVirtualTerminal()()
+	failed := false
+	for _, c := range environmentChecks() {
+		fmt.Println(c)
+		failed = failed || c.status == "FAIL"
+	}
+
+	fmt.Printf("\nRunning a %ds timer to measure timing...\n", seconds)
+	r := measureTiming(time.Duration(seconds) * time.Second)
+	fmt.Printf("Tick jitter:  mean %s, worst %s over %d ticks\n", r.meanJit.Round(time.Microsecond),
+		r.worstJit.Round(time.Microsecond), r.ticks)
+	when := "late"
+	if r.finish < 0 {
+		when = "early"
+	}
+	fmt.Printf("Completion:   %s %s (a few milliseconds late is expected)\n",
+		max(r.finish, -r.finish).Round(time.Microsecond), when)
+	fmt.Printf("Clock drift:  %s between the wall and monotonic clocks\n", r.clockSkew.Round(time.Microsecond))
+	if r.worstJit > 100*time.Millisecond {
+		fmt.Println("Ticks are arriving late; a loaded machine or a slow terminal can make the display stutter.")
+	}
+	if max(r.clockSkew, -r.clockSkew) > 100*time.Millisecond {
+		fmt.Println("The wall clock moved during the run (NTP step or suspend); timers follow the wall clock.")
+	}
+	if failed {
+		return exitError
+	}
+	return 0
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// finishTolerance is how far from its deadline the engine may complete
+// when ticks are late or a suspend moves the clock on.
+const finishTolerance = time.Millisecond
+
+// drive ticks m to completion the way the TUI does: regular ticks after
+// gap(i) instead of exactly a second, raced by one timed for the deadline.
+func drive(t *testing.T, m model, clk *fakeClock, gap func(i int) time.Duration) model {
+	t.Helper()
+	for i := 0; !m.done; i++ {
+		if i > 100000 {
+			t.Fatal("timer never completed")
+		}
+		wait := gap(i)
+		if left, ok := m.finalSecond(); ok {
+			wait = min(wait, left)
+		}
+		m, _ = m.tick(clk.advance(wait))
+	}
+	return m
+}
+
+func TestDeadlineTolerance(t *testing.T) {
+	tests := []struct {
+		name string
+		gap  func(i int) time.Duration
+	}{
+		{"exact ticks", func(int) time.Duration { return time.Second }},
+		{"steadily late ticks", func(int) time.Duration { return 1050 * time.Millisecond }},
+		{"early ticks", func(int) time.Duration { return 990 * time.Millisecond }},
+		{"jittery ticks", func(i int) time.Duration {
+			return []time.Duration{700, 1300, 1000, 1450, 550}[i%5] * time.Millisecond
+		}},
+		{"stalled event loop", func(i int) time.Duration {
+			if i == 10 {
+				return 25 * time.Second
+			}
+			return time.Second
+		}},
+		{"suspend mid-timer", func(i int) time.Duration {
+			if i == 30 {
+				return 3 * time.Minute
+			}
+			return time.Second
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, clk := startedModel(t, "10")
+			deadline := m.countdown.Deadline
+			m = drive(t, m, clk, tt.gap)
+			if off := clk.Now().Sub(deadline); off < -finishTolerance || off > finishTolerance {
+				t.Errorf("completed %s from the deadline, want within %s", off, finishTolerance)
+			}
+		})
+	}
+}
+
+// A timer whose deadline passes during a suspend completes on the first
+// tick after waking, not a full timer's length later.
+func TestDeadlineSuspendPastEnd(t *testing.T) {
+	m, clk := startedModel(t, "10")
+	m, _ = m.tick(clk.advance(time.Minute))
+	m, _ = m.tick(clk.advance(time.Hour))
+	if !m.done || m.timeRemaining != 0 {
+		t.Errorf("after waking past the deadline: done %v, left %s", m.done, m.timeRemaining)
+	}
+}
+
+// Time asleep counts against a running timer but not a paused one.
+func TestDeadlineSuspendRemaining(t *testing.T) {
+	m, clk := startedModel(t, "10")
+	m, _ = m.tick(clk.advance(time.Minute))
+	m, _ = m.tick(clk.advance(3 * time.Minute))
+	if m.timeRemaining != 6*time.Minute {
+		t.Fatalf("running across a suspend: left %s, want 6m0s", m.timeRemaining)
+	}
+
+	m = m.togglePause()
+	m, _ = m.tick(clk.advance(time.Hour))
+	m = m.togglePause()
+	m, _ = m.tick(clk.advance(time.Second))
+	if want := 6*time.Minute - time.Second; m.timeRemaining != want {
+		t.Errorf("paused across a suspend: left %s, want %s", m.timeRemaining, want)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseDuration reads what a user types to start a timer:
+//
+//	25        minutes
+//	1h30m     a Go duration, so values pasted from other tools work as-is
+//	90s
+//	12:30     minutes and seconds
+//	1:30:00   hours, minutes and seconds
+func parseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	var (
+		d   time.Duration
+		err error
+	)
+	if minutes, aerr := strconv.Atoi(s); aerr == nil {
+		d = time.Duration(minutes) * time.Minute
+	} else if strings.Contains(s, ":") {
+		d, err = parseClock(s)
+	} else {
+		d, err = time.ParseDuration(s)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if d < time.Second {
+		return 0, errors.New("duration must be at least one second")
+	}
+	return d, nil
+}
+
+// untilLayouts are the clock times accepted after "until", tried with
+// spaces removed and in lower case.
+var untilLayouts = []string{"15:04", "3:04pm", "3pm"}
+
+// parseTimerInput reads a duration, or "until <time>" to count down to
+// the next occurrence of a clock time, such as "until 14:30" or
+// "until 9:00pm". For the latter it also returns the layout the time was
+// given in, so the end can be shown the same way.
+func parseTimerInput(s string, now time.Time) (time.Duration, string, error) {
+	at, ok := strings.CutPrefix(strings.TrimSpace(s), "until ")
+	if !ok {
+		d, err := parseDuration(s)
+		return d, "", err
+	}
+	at = strings.ToLower(strings.ReplaceAll(at, " ", ""))
+	for _, layout := range untilLayouts {
+		t, err := time.Parse(layout, at)
+		if err != nil {
+			continue
+		}
+		end := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location())
+		if !end.After(now) {
+			end = time.Date(now.Year(), now.Month(), now.Day()+1, t.Hour(), t.Minute(), 0, 0, now.Location())
+		}
+		return max(end.Sub(now).Round(time.Second), time.Second), layout, nil
+	}
+	return 0, "", errors.New("use a time like 14:30, 9:00pm or 9pm after until")
+}
+
+// parseClock reads mm:ss or h:mm:ss. Every part but the first must be
+// below 60.
+func parseClock(s string) (time.Duration, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 {
+		return 0, errors.New("use mm:ss or h:mm:ss")
+	}
+	units := []time.Duration{time.Second, time.Minute, time.Hour}[:len(parts)]
+	var d time.Duration
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 || i > 0 && (n >= 60 || len(p) != 2) {
+			return 0, errors.New("use mm:ss or h:mm:ss")
+		}
+		d += time.Duration(n) * units[len(parts)-1-i]
+	}
+	return d, nil
+}
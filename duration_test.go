@@ -0,0 +1,70 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"25", 25 * time.Minute, true},
+		{" 5 ", 5 * time.Minute, true},
+		{"1h30m", 90 * time.Minute, true},
+		{"90s", 90 * time.Second, true},
+		{"12:30", 12*time.Minute + 30*time.Second, true},
+		{"90:00", 90 * time.Minute, true},
+		{"1:30:00", 90 * time.Minute, true},
+		{"0:00:05", 5 * time.Second, true},
+		{"0", 0, false},
+		{"0s", 0, false},
+		{"00:00", 0, false},
+		{"500ms", 0, false},
+		{"-5", 0, false},
+		{"-1m", 0, false},
+		{"-1:00", 0, false},
+		{"", 0, false},
+		{"abc", 0, false},
+		{"5 minutes", 0, false},
+		{"12:60", 0, false},
+		{"12:5", 0, false},
+		{"1:2:3:4", 0, false},
+		{"1::30", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := parseDuration(tt.in)
+		if tt.ok && (err != nil || got != tt.want) {
+			t.Errorf("parseDuration(%q) = %s, %v; want %s", tt.in, got, err, tt.want)
+		}
+		if !tt.ok && err == nil {
+			t.Errorf("parseDuration(%q) = %s, want an error", tt.in, got)
+		}
+	}
+}
+
+func TestParseTimerInputUntil(t *testing.T) {
+	now := time.Date(2026, 3, 2, 14, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in     string
+		want   time.Duration
+		layout string
+	}{
+		{"until 14:30", 30 * time.Minute, "15:04"},
+		{"until 3:15pm", 75 * time.Minute, "3:04pm"},
+		{"until 9 pm", 7 * time.Hour, "3pm"},
+		{"until 13:00", 23 * time.Hour, "15:04"},
+		{"25", 25 * time.Minute, ""},
+	}
+	for _, tt := range tests {
+		got, layout, err := parseTimerInput(tt.in, now)
+		if err != nil || got != tt.want || layout != tt.layout {
+			t.Errorf("parseTimerInput(%q) = %s, %q, %v; want %s, %q", tt.in, got, layout, err, tt.want, tt.layout)
+		}
+	}
+	if _, _, err := parseTimerInput("until lunch", now); err == nil {
+		t.Error(`parseTimerInput("until lunch") succeeded, want an error`)
+	}
+}
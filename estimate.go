@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// Estimates are per label, kept like sessions as "<seconds>\t<label>" in
+// the state directory. Actual time comes from the history, so it covers
+// every timer that ran under the label.
+
+func estimatesFile() string {
+	return filepath.Join(stateDir(), "estimates")
+}
+
+func loadEstimates() map[string]time.Duration {
+	return loadTotals(estimatesFile())
+}
+
+func setEstimate(label string, d time.Duration) error {
+	estimates := loadEstimates()
+	estimates[label] = d.Round(time.Second)
+	return saveTotals(estimatesFile(), estimates)
+}
+
+// labelTimes sums the actual time logged per label.
+func labelTimes(entries []historyEntry) map[string]time.Duration {
+	times := map[string]time.Duration{}
+	for _, e := range entries {
+		times[e.label] += e.actual
+	}
+	return times
+}
+
+// taskTime is the time spent on the current label so far, this timer
+// included.
+func (m model) taskTime() time.Duration {
+	d := m.labelTimes[m.label]
+	if !m.logged {
+		d += m.sittingTime()
+	}
+	return d
+}
+
+// estimateView reads e.g. "Estimate: 01:10:00 of 03:00:00 spent", or ""
+// when the label has no estimate.
+func (m model) estimateView() string {
+	est, ok := m.estimates[m.label]
+	if m.label == "" || !ok {
+		return ""
+	}
+	spent := m.taskTime()
+	line := fmt.Sprintf("Estimate: %s of %s spent", formatDuration(spent), formatDuration(est))
+	if spent > est {
+		return overtimeStyle.Render(line)
+	}
+	return line
+}
+
+func runEstimatesCommand(args []string) int {
+	if len(args) > 0 {
+		return usage("estimates")
+	}
+	estimates := loadEstimates()
+	if len(estimates) == 0 {
+		fmt.Println("No estimates yet. Give one with --label NAME --estimate 3h.")
+		return 0
+	}
+	times := labelTimes(loadHistory())
+	labels := make([]string, 0, len(estimates))
+	for l := range estimates {
+		labels = append(labels, l)
+	}
+	sort.Strings(labels)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "estimate\tactual\t\t")
+	for _, l := range labels {
+		est, actual := estimates[l], times[l]
+		fmt.Fprintf(w, "%s\t%s\t%.0f%%\t  %s\n", formatDuration(est), formatDuration(actual),
+			100*actual.Seconds()/max(est.Seconds(), 1), l)
+	}
+	w.Flush()
+	return 0
+}
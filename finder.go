@@ -0,0 +1,91 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const finderRows = 8
+
+// finder is a fuzzy-search overlay. It backs both the recent-timer search
+// and the command palette; pick decides what choosing an item does.
+type finder struct {
+	input    textinput.Model
+	items    []string
+	selected int
+	hint     string
+	pick     func(m model, item string) (tea.Model, tea.Cmd)
+}
+
+func newFinder(prompt, hint string, items []string, pick func(model, string) (tea.Model, tea.Cmd)) *finder {
+	ti := textinput.New()
+	ti.Prompt = prompt
+	ti.Focus()
+	return &finder{input: ti, items: items, hint: hint, pick: pick}
+}
+
+// showFinder opens f over the current screen.
+func (m model) showFinder(f *finder) (tea.Model, tea.Cmd) {
+	if m.theme.reduceMotion {
+		f.input.Cursor.SetMode(cursor.CursorStatic)
+	}
+	m.finder = f
+	return m, m.blink()
+}
+
+func (f *finder) matches() []string {
+	return fuzzyFilter(strings.TrimSpace(f.input.Value()), f.items)
+}
+
+// update returns the chosen item once Enter is pressed on a match.
+func (f *finder) update(msg tea.KeyMsg) (chosen string, cmd tea.Cmd) {
+	matches := f.matches()
+	switch msg.Type {
+	case tea.KeyUp:
+		if f.selected > 0 {
+			f.selected--
+		}
+		return "", nil
+	case tea.KeyDown:
+		if f.selected < min(len(matches), finderRows)-1 {
+			f.selected++
+		}
+		return "", nil
+	case tea.KeyEnter:
+		if f.selected < len(matches) {
+			return matches[f.selected], nil
+		}
+		return "", nil
+	}
+
+	f.input, cmd = f.input.Update(msg)
+	f.selected = 0
+	return "", cmd
+}
+
+func (f *finder) view() string {
+	var b strings.Builder
+	b.WriteString(f.input.View())
+	b.WriteString("\n\n")
+
+	matches := f.matches()
+	if len(matches) == 0 {
+		b.WriteString("  No matches\n")
+	}
+	for i, it := range matches {
+		if i == finderRows {
+			break
+		}
+		if i == f.selected {
+			b.WriteString(statusMessageStyle.Render("> " + it))
+		} else {
+			b.WriteString("  " + it)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n" + f.hint + "\n")
+	return b.String()
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// fuzzyScore reports whether every rune of pattern appears in s in order,
+// ignoring case. Higher scores favour consecutive runs and matches at the
+// start of words, which is what makes "52f" find "52-minute focus".
+func fuzzyScore(pattern, s string) (int, bool) {
+	pattern = strings.ToLower(pattern)
+	lower := strings.ToLower(s)
+
+	score, run := 0, 0
+	prev := ' '
+	for _, r := range lower {
+		if pattern == "" {
+			break
+		}
+		p, size := utf8.DecodeRuneInString(pattern)
+		if r == p {
+			run++
+			score += run
+			if strings.ContainsRune(" -_/.:", prev) {
+				score += 3
+			}
+			pattern = pattern[size:]
+		} else {
+			run = 0
+		}
+		prev = r
+	}
+	return score, pattern == ""
+}
+
+// fuzzyFilter returns the items matching pattern, best first. Ties keep
+// their original order.
+func fuzzyFilter(pattern string, items []string) []string {
+	type scored struct {
+		item  string
+		score int
+	}
+	var matches []scored
+	for _, it := range items {
+		if score, ok := fuzzyScore(pattern, it); ok {
+			matches = append(matches, scored{it, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	out := make([]string, len(matches))
+	for i, m := range matches {
+		out[i] = m.item
+	}
+	return out
+}
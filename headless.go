@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// runHeadless drives a timer without Bubble Tea, for scripts and cron
+// jobs. It prints a line at every tenth of the way (nothing when quiet)
+// and returns the timer when it completes or the process is interrupted.
+// Hooks, notifications, sinks and the history work as in the TUI, and
+// SIGUSR1 pauses and resumes. With --stdin, commands arrive on commands
+// and it runs until quit or until they end with no timer running.
+func runHeadless(m model, quiet bool, commands <-chan string) model {
+	// Nothing may write escape sequences into a log or a pipe.
+	m.bell = false
+	m.overtime = false
+	m.exitOnComplete = false
+	m.theme.reduceMotion = true
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(stop)
+	toggle := make(chan os.Signal, 1)
+	if sigs := pauseSignals(); len(sigs) > 0 {
+		signal.Notify(toggle, sigs...)
+		defer signal.Stop(toggle)
+	}
+
+	say := func(format string, args ...any) {
+		if !quiet {
+			fmt.Printf("%s  "+format+"\n", append([]any{time.Now().Format("15:04:05")}, args...)...)
+		}
+	}
+	announce := func(m model) {
+		what := formatDuration(m.countdown.Duration)
+		switch {
+		case m.pomodoro != nil:
+			what = m.pomodoroView() + ", " + what
+		case m.intervals != nil:
+			what = fmt.Sprintf("%s, round %d of %d, %s", m.intervals.kind, m.intervals.round, m.intervals.rounds, what)
+		}
+		if m.label != "" {
+			what = m.label + ": " + what
+		}
+		say("started %s", what)
+	}
+
+	var started time.Time
+	reported, ended := 0, false
+	// report says what changed since it last looked: a new timer or
+	// pomodoro or interval phase, a tenth of the way, or the end.
+	report := func(m model) {
+		switch {
+		case m.state == inputtingTime:
+			started = time.Time{}
+		case m.countdown.Started != started:
+			started, reported, ended = m.countdown.Started, 0, false
+			announce(m)
+		case m.done:
+			if !ended {
+				ended = true
+				say("done")
+			}
+		default:
+			if step := int(m.percent() * 10); step > reported && step < 10 {
+				reported = step
+				say("%3d%%  %s left", step*10, formatDuration(m.timeRemaining))
+			}
+		}
+	}
+	counting := func(m model) bool { return m.state != inputtingTime && !m.done }
+
+	report(m)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	var deadline <-chan time.Time
+	for counting(m) || commands != nil {
+		select {
+		case <-stop:
+			if counting(m) {
+				say("cancelled with %s left", formatDuration(m.timeRemaining))
+			}
+			return m
+		case <-toggle:
+			if !counting(m) {
+				continue
+			}
+			next, cmd := m.pauseOrResume()
+			m = next.(model)
+			runHeadlessCmd(cmd)
+			if m.state == paused {
+				say("paused with %s left", formatDuration(m.timeRemaining))
+			} else {
+				say("resumed")
+			}
+		case line, ok := <-commands:
+			if !ok {
+				commands = nil
+				continue
+			}
+			if line == "quit" {
+				return m
+			}
+			before := m.state
+			next, cmd := m.runCommand(line)
+			m = next.(model)
+			runHeadlessCmd(cmd)
+			if m.err != "" {
+				fmt.Fprintln(os.Stderr, m.err)
+				m.err = ""
+			}
+			switch {
+			case before == running && m.state == paused:
+				say("paused with %s left", formatDuration(m.timeRemaining))
+			case before == paused && m.state == running:
+				say("resumed")
+			case before != inputtingTime && m.state == inputtingTime:
+				say("stopped")
+			}
+			report(m)
+		case now := <-deadline:
+			var cmd tea.Cmd
+			m, cmd = m.tick(now)
+			runHeadlessCmd(cmd)
+			report(m)
+		case now := <-ticker.C:
+			var cmd tea.Cmd
+			m, cmd = m.tick(now)
+			runHeadlessCmd(cmd)
+			deadline = nil
+			if left, ok := m.finalSecond(); ok {
+				deadline = time.After(left)
+			}
+			report(m)
+		}
+	}
+	return m
+}
+
+// runHeadlessCmd runs the model's commands to completion in place of the
+// Bubble Tea runtime. Failed deliveries are reported on stderr; other
+// messages only matter to the TUI.
+func runHeadlessCmd(cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	switch msg := cmd().(type) {
+	case tea.BatchMsg:
+		for _, c := range msg {
+			runHeadlessCmd(c)
+		}
+	case deliveryErrMsg:
+		fmt.Fprintf(os.Stderr, "%s failed: %s\n", msg.channel, msg.err)
+	}
+}
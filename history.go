@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// The history has one entry per timer that ran, completed or not. It is
+// replayed from the event journal; older versions appended the entries to
+// history.tsv instead, one tab-separated line each:
+//
+//	start  end  completed|broken|cancelled  planned  actual  paused  label
+//
+// Times are RFC 3339 and durations Go durations. Actual time excludes
+// pauses and includes overtime.
+
+const (
+	statusCompleted = "completed"
+	statusCancelled = "cancelled"
+	statusBroken    = "broken" // completed, but over the pause budget
+)
+
+type historyEntry struct {
+	id      string // the timer in the journal; empty for history.tsv
+	start   time.Time
+	end     time.Time
+	status  string
+	planned time.Duration
+	actual  time.Duration
+	paused  time.Duration
+	label   string
+	pauses  [][2]time.Time // from and to; only replayed from the journal
+}
+
+func historyFile() string {
+	return filepath.Join(dataDir(), "history.tsv")
+}
+
+func (e historyEntry) String() string {
+	label := strings.Join(strings.Fields(e.label), " ")
+	return strings.Join([]string{
+		e.start.Format(time.RFC3339), e.end.Format(time.RFC3339), e.status,
+		e.planned.String(), e.actual.String(), e.paused.String(), label,
+	}, "\t")
+}
+
+func parseHistoryEntry(line string) (historyEntry, error) {
+	f := strings.SplitN(line, "\t", 7)
+	if len(f) != 7 {
+		return historyEntry{}, fmt.Errorf("expected 7 fields, got %d", len(f))
+	}
+	e := historyEntry{status: f[2], label: f[6]}
+	var err error
+	if e.start, err = time.Parse(time.RFC3339, f[0]); err != nil {
+		return e, err
+	}
+	if e.end, err = time.Parse(time.RFC3339, f[1]); err != nil {
+		return e, err
+	}
+	for i, d := range []*time.Duration{&e.planned, &e.actual, &e.paused} {
+		if *d, err = time.ParseDuration(f[3+i]); err != nil {
+			return e, err
+		}
+	}
+	return e, nil
+}
+
+// loadHistory returns the history oldest first: the entries logged by
+// older versions followed by those replayed from the event journal. Lines
+// that do not parse are skipped, so a hand edit gone wrong costs one entry
+// rather than all.
+func loadHistory() []historyEntry {
+	var out []historyEntry
+	if f, err := os.Open(historyFile()); err == nil {
+		defer f.Close()
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			if e, err := parseHistoryEntry(sc.Text()); err == nil {
+				out = append(out, e)
+			}
+		}
+	}
+	out = append(out, replayEvents(loadEvents())...)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].start.Before(out[j].start) })
+	return out
+}
+
+func (m model) historyEntry(status string) historyEntry {
+	if status == statusCompleted && m.brokenPauses() {
+		status = statusBroken
+	}
+	return historyEntry{
+		start:   m.countdown.Started,
+		end:     m.clock.Now(),
+		status:  status,
+		planned: m.countdown.Duration,
+		actual:  m.sittingTime(),
+		paused:  m.pausedTotal().Round(time.Second),
+		label:   m.historyLabel(),
+	}
+}
+
+// historyLabel keeps pomodoro breaks and interval rests apart from the
+// work they follow.
+func (m model) historyLabel() string {
+	if m.pomodoro != nil && (m.label == "" || m.pomodoro.phase != work) {
+		return m.pomodoro.phase.String()
+	}
+	if m.intervals != nil && (m.label == "" || m.intervals.kind != intervalWork) {
+		return m.intervals.kind.String()
+	}
+	return m.label
+}
+
+// recordHistory logs the current timer once, in the background.
+func (m model) recordHistory(status string) (model, tea.Cmd) {
+	if m.state == inputtingTime || m.logged {
+		return m, nil
+	}
+	m.logged = true
+	e := m.historyEntry(status)
+	m.labelTimes[e.label] += e.actual
+	return m.journal("end", e.status)
+}
+
+// recordFinished logs the timer before it is dismissed: as completed if
+// it got there, which for one left in overtime includes the overrun, and
+// otherwise as cancelled.
+func (m model) recordFinished() (model, tea.Cmd) {
+	if m.done {
+		return m.recordHistory(statusCompleted)
+	}
+	return m.recordHistory(statusCancelled)
+}
+
+// logUnfinished records a timer that was still running, or left in
+// overtime, when the program quit. It runs after the TUI has exited, so
+// it writes directly.
+func logUnfinished(m model) {
+	if m.state != inputtingTime && !m.logged {
+		status := statusCancelled
+		if m.done {
+			status = statusCompleted
+		}
+		_, events := m.journalEvents("end", m.historyEntry(status).status)
+		if err := appendEvents(events...); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+}
+
+func runHistoryCommand(args []string) int {
+	if len(args) == 1 && (args[0] == "browse" || args[0] == "edit") {
+		return runHistoryScreen()
+	}
+	if len(args) > 0 {
+		return usage("history")
+	}
+	entries := loadHistory()
+	if len(entries) == 0 {
+		fmt.Println("No timers recorded yet.")
+		return 0
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s / %s\t%s\n", e.start.Local().Format("Mon Jan 2 15:04"),
+			e.status, formatDuration(e.actual), formatDuration(e.planned), e.label)
+	}
+	w.Flush()
+	return 0
+}
@@ -0,0 +1,428 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/codytheroux96/progress-timer/timer"
+)
+
+const (
+	historyRows = 12
+	// historySideBySide is the width from which the detail pane sits
+	// beside the list rather than under it.
+	historySideBySide = 120
+)
+
+// historyScreen is `history browse` and `history edit`: the timers newest
+// first, filtered by label, status or date, with the selected one's
+// details and notes alongside. A timer can be fixed after the fact, such
+// as one left running by mistake, or deleted. Edits are written to the
+// journal, so the original timings stay there too. Timers from
+// history.tsv predate the journal and aren't listed.
+type historyScreen struct {
+	all      []historyEntry // newest first
+	entries  []historyEntry // those the filter lets through
+	notes    map[string][]string
+	selected int
+	width    int
+	clock    clock
+	query    string
+	filter   *textinput.Model // the filter being typed, if any
+	prompt   *textinput.Model
+	action   string // what the prompt is for: trim, split or relabel
+	deleting bool   // waiting for y to confirm a delete
+	message  string
+	failed   bool
+}
+
+// editedMsg reports an edit written to the journal.
+type editedMsg struct {
+	message string
+	err     error
+}
+
+func newHistoryScreen(c clock) historyScreen {
+	s := historyScreen{clock: c}
+	return s.reload("")
+}
+
+// reload reads the history and notes again, keeping the timer with id
+// selected.
+func (s historyScreen) reload(id string) historyScreen {
+	s.all = s.all[:0:0]
+	for _, e := range loadHistory() {
+		if e.id != "" {
+			s.all = append(s.all, e)
+		}
+	}
+	slices.Reverse(s.all)
+	s.notes = journalNotes()
+	return s.refilter(id)
+}
+
+// refilter applies the query, keeping the timer with id selected if it
+// is still listed.
+func (s historyScreen) refilter(id string) historyScreen {
+	s.entries = s.entries[:0:0]
+	for _, e := range s.all {
+		if matchesQuery(e, s.query) {
+			s.entries = append(s.entries, e)
+		}
+	}
+	s.selected = min(s.selected, max(len(s.entries)-1, 0))
+	for i, e := range s.entries {
+		if e.id == id {
+			s.selected = i
+		}
+	}
+	return s
+}
+
+// matchesQuery reports whether every word of query matches e: a date or
+// its start, such as 2026-03 or 2026-03-02, matches the day the timer
+// started, anything else its label or status, ignoring case.
+func matchesQuery(e historyEntry, query string) bool {
+	for _, w := range strings.Fields(strings.ToLower(query)) {
+		if strings.Trim(w, "0123456789-") == "" {
+			if !strings.HasPrefix(e.start.Local().Format(time.DateOnly), w) {
+				return false
+			}
+		} else if !strings.Contains(strings.ToLower(e.label), w) && e.status != w {
+			return false
+		}
+	}
+	return true
+}
+
+// journalNotes reads the journal's notes, keyed by the minute their timer
+// started as journalEntry dates them. Reviews are left out.
+func journalNotes() map[string][]string {
+	notes := map[string][]string{}
+	f, err := os.Open(journalFile())
+	if err != nil {
+		return notes
+	}
+	defer f.Close()
+	const stamp = len("2006-01-02 15:04")
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line, ok := strings.CutPrefix(sc.Text(), "- ")
+		if !ok || len(line) <= stamp || strings.HasPrefix(line[stamp:], " review") {
+			continue
+		}
+		if _, note, ok := strings.Cut(line[stamp:], ": "); ok {
+			notes[line[:stamp]] = append(notes[line[:stamp]], note)
+		}
+	}
+	return notes
+}
+
+func (s historyScreen) current() (historyEntry, bool) {
+	if s.selected >= len(s.entries) {
+		return historyEntry{}, false
+	}
+	return s.entries[s.selected], true
+}
+
+func (s historyScreen) Init() tea.Cmd { return nil }
+
+func (s historyScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case editedMsg:
+		s.message, s.failed = msg.message, msg.err != nil
+		if msg.err != nil {
+			s.message = msg.err.Error()
+		}
+		e, _ := s.current()
+		return s.reload(e.id), nil
+	case tea.WindowSizeMsg:
+		s.width = msg.Width
+		return s, nil
+	case tea.KeyMsg:
+		switch {
+		case s.filter != nil:
+			return s.updateFilter(msg)
+		case s.prompt != nil:
+			return s.updatePrompt(msg)
+		case s.deleting:
+			return s.confirmDelete(msg)
+		}
+		return s.key(msg)
+	}
+	var cmd tea.Cmd
+	if s.filter != nil {
+		ti := *s.filter
+		ti, cmd = ti.Update(msg)
+		s.filter = &ti
+	} else if s.prompt != nil {
+		ti := *s.prompt
+		ti, cmd = ti.Update(msg)
+		s.prompt = &ti
+	}
+	return s, cmd
+}
+
+func (s historyScreen) key(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	e, ok := s.current()
+	switch msg.String() {
+	case "q", "esc", "ctrl+c":
+		return s, tea.Quit
+	case "up", "k":
+		s.selected = max(s.selected-1, 0)
+	case "down", "j":
+		s.selected = min(s.selected+1, max(len(s.entries)-1, 0))
+	case "/":
+		ti := textinput.New()
+		ti.Prompt = "Filter: "
+		ti.Placeholder = "label, status or date such as 2026-03"
+		ti.SetValue(s.query)
+		ti.CharLimit = 80
+		ti.Width = 60
+		ti.Focus()
+		s.filter, s.message = &ti, ""
+		return s, textinput.Blink
+	case "d":
+		if ok {
+			s.deleting, s.message = true, ""
+		}
+	case "t", "s", "l":
+		if !ok {
+			return s, nil
+		}
+		ti := textinput.New()
+		switch msg.String() {
+		case "t":
+			s.action, ti.Prompt = "trim", "End at: "
+			ti.Placeholder = "a time such as 10:15, or how long after the start, such as 45m"
+		case "s":
+			s.action, ti.Prompt = "split", "Split at: "
+			ti.Placeholder = "a time such as 10:15, or how long after the start, such as 45m"
+		case "l":
+			s.action, ti.Prompt = "relabel", "Label: "
+			ti.SetValue(e.label)
+		}
+		ti.CharLimit = 80
+		ti.Width = 60
+		ti.Focus()
+		s.prompt, s.message = &ti, ""
+		return s, textinput.Blink
+	}
+	return s, nil
+}
+
+// updateFilter filters the list as the query is typed. Enter keeps the
+// filter; Esc drops it.
+func (s historyScreen) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	e, _ := s.current()
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return s, tea.Quit
+	case tea.KeyEnter:
+		s.filter = nil
+		return s, nil
+	case tea.KeyEsc:
+		s.filter, s.query = nil, ""
+		return s.refilter(e.id), nil
+	}
+	var cmd tea.Cmd
+	ti := *s.filter
+	ti, cmd = ti.Update(msg)
+	s.filter = &ti
+	s.query = ti.Value()
+	return s.refilter(e.id), cmd
+}
+
+func (s historyScreen) confirmDelete(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	s.deleting = false
+	e, ok := s.current()
+	if msg.String() != "y" || !ok {
+		return s, nil
+	}
+	return s, s.save(event{at: s.clock.Now(), timer: e.id, kind: "delete"}, e)
+}
+
+func (s historyScreen) updatePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return s, tea.Quit
+	case tea.KeyEsc:
+		s.prompt = nil
+		return s, nil
+	case tea.KeyEnter:
+		e, _ := s.current()
+		ev, err := s.edit(e, s.action, strings.TrimSpace(s.prompt.Value()))
+		if err != nil {
+			s.message, s.failed = err.Error(), true
+			return s, nil
+		}
+		s.prompt, s.message = nil, ""
+		return s, s.save(ev, e)
+	}
+	var cmd tea.Cmd
+	ti := *s.prompt
+	ti, cmd = ti.Update(msg)
+	s.prompt = &ti
+	return s, cmd
+}
+
+// save writes ev for e to the journal in the background.
+func (s historyScreen) save(ev event, e historyEntry) tea.Cmd {
+	done := map[string]string{"trim": "Trimmed", "split": "Split", "relabel": "Relabelled", "delete": "Deleted"}[ev.kind] +
+		" the timer of " + e.start.Local().Format("Mon Jan 2 15:04")
+	return func() tea.Msg {
+		return editedMsg{message: done, err: appendEvents(ev)}
+	}
+}
+
+// edit makes the journal event for action on e, given what was typed.
+func (s historyScreen) edit(e historyEntry, action, input string) (event, error) {
+	ev := event{at: s.clock.Now(), timer: e.id, kind: action}
+	if action == "relabel" {
+		ev.label = input
+		return ev, nil
+	}
+	at, err := editTime(e, input)
+	if err != nil {
+		return ev, err
+	}
+	ev.value = at.UTC().Format(time.RFC3339Nano)
+	return ev, nil
+}
+
+// editTime reads a trim or split point: a clock time, the first after the
+// start, or how long after the start. It must fall inside the timer.
+func editTime(e historyEntry, input string) (time.Time, error) {
+	var at time.Time
+	if t, err := time.Parse("15:04", input); err == nil {
+		start := e.start.Local()
+		at = time.Date(start.Year(), start.Month(), start.Day(), t.Hour(), t.Minute(), 0, 0, time.Local)
+		if !at.After(start) {
+			at = at.AddDate(0, 0, 1)
+		}
+	} else if d, err := parseDuration(input); err == nil {
+		at = e.start.Add(d)
+	} else {
+		return at, fmt.Errorf("%q is not a time like 10:15 or a duration like 45m", input)
+	}
+	if !at.After(e.start) || !at.Before(e.end) {
+		return at, fmt.Errorf("%s is not between %s and %s", at.Local().Format("15:04"),
+			e.start.Local().Format("15:04"), e.end.Local().Format("15:04"))
+	}
+	return at, nil
+}
+
+func (s historyScreen) row(e historyEntry) string {
+	return fmt.Sprintf("%s–%s  %-9s  %8s / %-8s  %s", e.start.Local().Format("Mon Jan 2 15:04"),
+		e.end.Local().Format("15:04"), e.status, formatDuration(e.actual), formatDuration(e.planned), e.label)
+}
+
+// detail is the selected timer in full: its timings, each pause and the
+// notes written for it.
+func (s historyScreen) detail(e historyEntry) string {
+	bold := lipgloss.NewStyle().Bold(true)
+	var b strings.Builder
+	b.WriteString(bold.Render(e.start.Local().Format("Monday January 2, 2006")) + "\n")
+	fmt.Fprintf(&b, "%s–%s\n\n", e.start.Local().Format("15:04"), e.end.Local().Format("15:04"))
+	label := e.label
+	if label == "" {
+		label = "(no label)"
+	}
+	for _, f := range [][2]string{
+		{"Label", label},
+		{"Status", e.status},
+		{"Planned", formatDuration(e.planned)},
+		{"Actual", formatDuration(e.actual)},
+		{"Paused", fmt.Sprintf("%d× for %s", len(e.pauses), formatDuration(e.paused))},
+	} {
+		fmt.Fprintf(&b, "%-8s %s\n", f[0], f[1])
+	}
+	for _, p := range e.pauses {
+		fmt.Fprintf(&b, "         %s–%s\n", p[0].Local().Format("15:04:05"), p[1].Local().Format("15:04:05"))
+	}
+	if notes := s.notes[e.start.Local().Format("2006-01-02 15:04")]; len(notes) > 0 {
+		b.WriteString("\n" + bold.Render("Notes") + "\n")
+		wrap := lipgloss.NewStyle().Width(48)
+		for _, n := range notes {
+			b.WriteString(wrap.Render(n) + "\n")
+		}
+	}
+	return lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(0, 1).Render(strings.TrimRight(b.String(), "\n"))
+}
+
+func (s historyScreen) View() string {
+	var list strings.Builder
+	title := "History"
+	if s.query != "" {
+		title += fmt.Sprintf(" matching %q, %d of %d", s.query, len(s.entries), len(s.all))
+	}
+	list.WriteString(lipgloss.NewStyle().Bold(true).Render(title) + "\n\n")
+	switch {
+	case len(s.all) == 0:
+		list.WriteString("No timers in the journal.\n")
+	case len(s.entries) == 0:
+		list.WriteString("No timers match.\n")
+	}
+	// A window of rows that keeps the selection in view.
+	from := max(min(s.selected-historyRows/2, len(s.entries)-historyRows), 0)
+	for i := from; i < min(from+historyRows, len(s.entries)); i++ {
+		if i == s.selected {
+			list.WriteString(statusMessageStyle.Render("> "+s.row(s.entries[i])) + "\n")
+		} else {
+			list.WriteString("  " + s.row(s.entries[i]) + "\n")
+		}
+	}
+
+	body := list.String()
+	if e, ok := s.current(); ok {
+		if s.width >= historySideBySide {
+			body = lipgloss.JoinHorizontal(lipgloss.Top, body, "  ", s.detail(e))
+		} else {
+			body += "\n" + s.detail(e) + "\n"
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(body + "\n")
+	if s.message != "" {
+		style := lipgloss.NewStyle().Faint(true)
+		if s.failed {
+			style = errorStyle
+		}
+		b.WriteString(style.Render(s.message) + "\n")
+	}
+	switch {
+	case s.filter != nil:
+		b.WriteString(s.filter.View() + "\n")
+		b.WriteString("Enter keeps the filter, Esc clears it\n")
+	case s.prompt != nil:
+		b.WriteString(s.prompt.View() + "\n")
+		b.WriteString("Enter saves, Esc cancels\n")
+	case s.deleting:
+		b.WriteString(errorStyle.Render("Delete this timer from the history? y deletes, any other key keeps it") + "\n")
+	default:
+		b.WriteString("↑/↓ select · / filter · t trim the end · s split in two · l relabel · d delete · q quit\n")
+	}
+	return lipgloss.NewStyle().Margin(1, 2).Render(b.String())
+}
+
+func runHistoryScreen() int {
+	cfg, err := loadConfig(configFile())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitInvalid
+	}
+	setStyles(cfg.theme())
+	if _, err := tea.NewProgram(newHistoryScreen(timer.SystemClock{}), cfg.programOptions()...).Run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	return 0
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// overtimeModel is a one-minute timer with overtime on, ticked to
+// elapsed. The start is journaled with the end, not by the first tick.
+func overtimeModel(t *testing.T, overtime bool, elapsed time.Duration) (model, *fakeClock) {
+	t.Helper()
+	m, clk := startedModel(t, "1")
+	m.overtime = overtime
+	m.startHooked = true
+	for left := elapsed; left > 0; left -= time.Second {
+		m, _ = m.tick(clk.advance(min(left, time.Second)))
+	}
+	return m, clk
+}
+
+func TestHistoryRecordsOverrun(t *testing.T) {
+	m, _ := overtimeModel(t, true, 90*time.Second)
+	if !m.done || m.logged {
+		t.Fatalf("done %v, logged %v; want a completed timer not yet logged while in overtime", m.done, m.logged)
+	}
+	// Dismissed 30 seconds into overtime.
+	_, logged := m.recordFinished()
+	logged()
+
+	h := loadHistory()
+	if len(h) != 1 {
+		t.Fatalf("%d history entries, want 1", len(h))
+	}
+	e := h[0]
+	if e.status != statusCompleted || e.planned != time.Minute || e.actual != 90*time.Second {
+		t.Errorf("got %s planned %s actual %s, want completed planned 1m0s actual 1m30s", e.status, e.planned, e.actual)
+	}
+}
+
+func TestHistoryWithoutOvertime(t *testing.T) {
+	m, _ := overtimeModel(t, false, 90*time.Second)
+	if !m.done || !m.logged {
+		t.Errorf("done %v, logged %v; want a timer logged at its deadline", m.done, m.logged)
+	}
+}
+
+func TestHistoryOvertimeAtQuit(t *testing.T) {
+	m, _ := overtimeModel(t, true, 2*time.Minute)
+	logUnfinished(m)
+	h := loadHistory()
+	if len(h) != 1 || h[0].status != statusCompleted || h[0].actual != 2*time.Minute {
+		t.Fatalf("history %+v, want one completed entry of 2m0s", h)
+	}
+}
+
+func TestStatsOverran(t *testing.T) {
+	at := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	entries := []historyEntry{
+		{start: at, status: statusCompleted, planned: 25 * time.Minute, actual: 25 * time.Minute},
+		{start: at, status: statusCompleted, planned: 25 * time.Minute, actual: 31 * time.Minute},
+		{start: at, status: statusBroken, planned: 50 * time.Minute, actual: 54 * time.Minute},
+		{start: at, status: statusCancelled, planned: 5 * time.Minute, actual: 9 * time.Minute},
+	}
+	s := computeStats(entries, nil, at)
+	if s.overran != 2 || s.overrun != 10*time.Minute {
+		t.Errorf("overran %d by %s, want 2 by 10m0s", s.overran, s.overrun)
+	}
+}
+
+func TestStatsByHourAndWeekday(t *testing.T) {
+	mon := time.Date(2026, 3, 2, 9, 40, 0, 0, time.UTC)
+	entries := []historyEntry{
+		{start: mon, status: statusCompleted, actual: 25 * time.Minute},
+		{start: mon.Add(24 * time.Hour), status: statusCompleted, actual: 50 * time.Minute},
+		{start: mon.Add(5 * time.Hour), status: statusCancelled, actual: 10 * time.Minute},
+	}
+	s := computeStats(entries, nil, mon)
+	if s.perHour[9] != 75*time.Minute || s.perHour[14] != 10*time.Minute {
+		t.Errorf("09:00 %s, 14:00 %s, want 1h15m0s and 10m0s", s.perHour[9], s.perHour[14])
+	}
+	if s.perWeekday[time.Monday] != 35*time.Minute || s.perWeekday[time.Tuesday] != 50*time.Minute {
+		t.Errorf("Monday %s, Tuesday %s, want 35m0s and 50m0s", s.perWeekday[time.Monday], s.perWeekday[time.Tuesday])
+	}
+	view := statsView{stats: s, now: mon, ascii: true}.View()
+	for _, want := range []string{"By hour", "09:00", "14:00", "By weekday", "Tuesday"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("stats view lacks %q", want)
+		}
+	}
+	// Hour rows are padded to ten columns, durations aren't.
+	if strings.Contains(view, "08:00     ") || strings.Contains(view, "15:00     ") {
+		t.Error("stats view charts hours outside those with time")
+	}
+}
+
+func TestHistoryAdjustBeforeFirstTick(t *testing.T) {
+	m, clk := startedModel(t, "1")
+	next, journaled := m.adjust(time.Minute)
+	journaled()
+	m = next.(model)
+	m, _ = m.tick(clk.advance(90 * time.Second))
+	_, logged := m.recordFinished()
+	logged()
+	h := loadHistory()
+	if len(h) != 1 || h[0].planned != 2*time.Minute || h[0].actual != 90*time.Second {
+		t.Fatalf("history %+v, want one entry planned for 2m0s", h)
+	}
+}
+
+func TestReplayEdits(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	id := start.Format(time.RFC3339Nano)
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+	stamp := func(d time.Duration) string { return at(d).Format(time.RFC3339Nano) }
+	events := []event{
+		{at: start, timer: id, kind: "start", value: "25m0s", label: "Focus"},
+		{at: at(10 * time.Minute), timer: id, kind: "pause"},
+		{at: at(20 * time.Minute), timer: id, kind: "resume"},
+		{at: at(3 * time.Hour), timer: id, kind: "end", value: statusCompleted},
+		// Left running: the work ended after an hour, then came an hour
+		// of something else.
+		{at: at(4 * time.Hour), timer: id, kind: "split", value: stamp(time.Hour)},
+		{at: at(4 * time.Hour), timer: stamp(time.Hour), kind: "trim", value: stamp(2 * time.Hour)},
+		{at: at(4 * time.Hour), timer: stamp(time.Hour), kind: "relabel", label: "Email"},
+		{at: at(4 * time.Hour), timer: id, kind: "trim", value: stamp(5 * time.Hour)}, // past the end
+		{at: at(5 * time.Hour), timer: "unknown", kind: "relabel", label: "lost"},
+	}
+	h := replayEvents(events)
+	if len(h) != 2 {
+		t.Fatalf("%d entries, want 2: %+v", len(h), h)
+	}
+	first, second := h[0], h[1]
+	if first.label != "Focus" || first.actual != 50*time.Minute || first.paused != 10*time.Minute || !first.end.Equal(at(time.Hour)) {
+		t.Errorf("first part %+v, want Focus for 50m0s paused 10m0s, ending at 10:00", first)
+	}
+	if second.label != "Email" || second.actual != time.Hour || second.planned != time.Hour || second.paused != 0 ||
+		second.status != statusCompleted || second.id != stamp(time.Hour) {
+		t.Errorf("second part %+v, want Email for 1h0m0s planned 1h0m0s, unpaused", second)
+	}
+}
+
+func TestHistoryScreenEdit(t *testing.T) {
+	m, clk := overtimeModel(t, true, 2*time.Minute)
+	_, logged := m.recordFinished()
+	logged()
+
+	var s tea.Model = newHistoryScreen(clk)
+	press := func(keys ...string) {
+		for _, k := range keys {
+			msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+			if k == "enter" {
+				msg = tea.KeyMsg{Type: tea.KeyEnter}
+			}
+			var cmd tea.Cmd
+			s, cmd = s.Update(msg)
+			// Other keys only blink the cursor, which would wait.
+			if k == "enter" && cmd != nil {
+				s, _ = s.Update(cmd())
+			}
+		}
+	}
+	press("t", "3m", "enter")
+	if hs := s.(historyScreen); !hs.failed || !strings.Contains(hs.message, "is not between") {
+		t.Errorf("trimming past the end: message %q", hs.message)
+	}
+	s, _ = s.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	press("t", "90s", "enter")
+	if h := loadHistory(); len(h) != 1 || h[0].actual != 90*time.Second {
+		t.Fatalf("history after trimming %+v, want 1m30s", h)
+	}
+	press("l", "Review", "enter")
+	if h := loadHistory(); len(h) != 1 || h[0].label != "Review" {
+		t.Errorf("history after relabelling %+v, want the label Review", h)
+	}
+}
+
+func TestHistoryScreenBrowse(t *testing.T) {
+	isolate(t)
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.Local)
+	var events []event
+	for i, label := range []string{"Write", "Review"} {
+		at := start.Add(time.Duration(i) * time.Hour)
+		id := at.UTC().Format(time.RFC3339Nano)
+		events = append(events,
+			event{at: at, timer: id, kind: "start", value: "25m0s", label: label},
+			event{at: at.Add(5 * time.Minute), timer: id, kind: "pause"},
+			event{at: at.Add(7 * time.Minute), timer: id, kind: "resume"},
+			event{at: at.Add(27 * time.Minute), timer: id, kind: "end", value: statusCompleted})
+	}
+	if err := appendEvents(events...); err != nil {
+		t.Fatal(err)
+	}
+	runHeadlessCmd(appendJournal("2026-03-02 09:00 Write (25:00): outline done"))
+
+	s := newHistoryScreen(&fakeClock{now: start.Add(3 * time.Hour)})
+	if len(s.entries) != 2 || s.entries[0].label != "Review" {
+		t.Fatalf("entries %+v, want Review then Write", s.entries)
+	}
+	// Only keys that save run their command; the rest would wait on a
+	// cursor blink.
+	press := func(msg tea.KeyMsg, saves bool) {
+		next, cmd := s.Update(msg)
+		s = next.(historyScreen)
+		if saves && cmd != nil {
+			next, _ = s.Update(cmd())
+			s = next.(historyScreen)
+		}
+	}
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")}, false)
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("wri 2026-03")}, false)
+	press(tea.KeyMsg{Type: tea.KeyEnter}, false)
+	if len(s.entries) != 1 || s.entries[0].label != "Write" {
+		t.Fatalf("filtered entries %+v, want Write alone", s.entries)
+	}
+	detail := s.detail(s.entries[0])
+	for _, want := range []string{"outline done", "1× for 02:00", "09:05:00–09:07:00"} {
+		if !strings.Contains(detail, want) {
+			t.Errorf("detail lacks %q:\n%s", want, detail)
+		}
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")}, false)
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")}, true)
+	if h := loadHistory(); len(h) != 1 || h[0].label != "Review" {
+		t.Errorf("history after deleting %+v, want Review alone", h)
+	}
+	if len(s.entries) != 0 || len(s.all) != 1 {
+		t.Errorf("screen after deleting: %d shown of %d, want 0 of 1", len(s.entries), len(s.all))
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const minTickHookInterval = time.Second
+
+// tickHookChannel is the on_tick hook's delivery channel.
+const tickHookChannel = "Hook tick"
+
+type hookEvent int
+
+const (
+	eventStart hookEvent = iota
+	eventPause
+	eventResume
+	eventComplete
+	eventPhase
+	eventEscalate
+	numHookEvents
+)
+
+func (e hookEvent) String() string {
+	return [...]string{"start", "pause", "resume", "complete", "phase", "escalate"}[e]
+}
+
+// eventHookOption is the config entry for one event's hook.
+func eventHookOption(e hookEvent, comment string) configOption {
+	return configOption{
+		key:     "hooks.on_" + e.String(),
+		comment: comment,
+		set: func(c *config, v string) error {
+			c.eventHooks[e] = v
+			return nil
+		},
+	}
+}
+
+// runHook starts c in the background. A hook that can't start is a
+// failed delivery on channel; one that exits unsuccessfully may do so
+// long after, so it is only logged. Output is discarded because anything
+// written to the terminal would tear through the TUI.
+func runHook(channel string, c *exec.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		if err := c.Start(); err != nil {
+			return deliveryFailed(channel, err)
+		}
+		go func() {
+			if err := c.Wait(); err != nil {
+				_ = logDelivery(time.Now(), channel, "failed", err.Error())
+			}
+		}()
+		return nil
+	}
+}
+
+// phaseName is the pomodoro or interval phase or the template step, or
+// "" for a plain timer.
+func (m model) phaseName() string {
+	switch {
+	case m.pomodoro != nil:
+		return m.pomodoro.phase.String()
+	case m.intervals != nil:
+		return m.intervals.kind.String()
+	case m.chain != nil:
+		return m.chain.current().label
+	}
+	return ""
+}
+
+// eventHook runs the command configured for e. The timer is described in
+// the environment: TIMER_EVENT, TIMER_LABEL, TIMER_DURATION and
+// TIMER_REMAINING (in seconds) and TIMER_PHASE for pomodoros and
+// intervals.
+func (m model) eventHook(e hookEvent) tea.Cmd {
+	cmdline := m.eventHooks[e]
+	if cmdline == "" {
+		return nil
+	}
+	channel, key := "Hook "+e.String(), ""
+	switch e {
+	case eventEscalate:
+		key = m.deliveryKey(fmt.Sprintf("escalation after %s", m.alertingFor))
+	case eventStart, eventComplete, eventPhase:
+		// A timer starts, completes or changes phase once.
+		key = m.deliveryKey(e.String())
+	}
+	if m.dryRun {
+		return m.dispatch.dryRun(channel, key, cmdline)
+	}
+	c := shellCommand(cmdline)
+	c.Env = append(os.Environ(),
+		"TIMER_EVENT="+e.String(),
+		"TIMER_LABEL="+m.label,
+		"TIMER_DURATION="+strconv.Itoa(int(m.countdown.Duration.Seconds())),
+		"TIMER_REMAINING="+strconv.Itoa(int(m.timeRemaining.Seconds())),
+		"TIMER_PHASE="+m.phaseName(),
+	)
+	return m.dispatch.runHook(channel, key, c)
+}
+
+// deliveryKey names what happened to the current timer, for the
+// dispatcher to tell a repeat from a new event.
+func (m model) deliveryKey(what string) string {
+	return what + " of the timer started " + m.countdown.Started.Format(time.RFC3339Nano)
+}
+
+// startHook fires the start hook, or the phase hook when a pomodoro or
+// interval plan moves on, once the timer is counting: after any warm-up.
+func (m model) startHook() (model, tea.Cmd) {
+	if m.state == inputtingTime || m.warmupLeft > 0 || m.startHooked {
+		return m, nil
+	}
+	m.startHooked = true
+	m.publish("started")
+	m, journaled := m.journal("start", "")
+	if m.phaseChanged {
+		return m, tea.Batch(journaled, m.eventHook(eventPhase))
+	}
+	return m, tea.Batch(journaled, m.eventHook(eventStart))
+}
+
+// pauseOrResume toggles the pause and runs the matching hook.
+func (m model) pauseOrResume() (tea.Model, tea.Cmd) {
+	// A finished timer has nothing left to pause, unless it is counting
+	// overtime.
+	if m.done && !m.overtime {
+		return m, nil
+	}
+	m = m.togglePause()
+	if m.state == paused {
+		m.publish("paused")
+		m, journaled := m.journal("pause", "")
+		return m, tea.Batch(journaled, m.eventHook(eventPause))
+	}
+	m.publish("resumed")
+	m, journaled := m.journal("resume", "")
+	return m, tea.Batch(journaled, m.eventHook(eventResume))
+}
+
+// tickHook runs the on-tick command with the remaining seconds, at most
+// once per tickHookInterval.
+func (m model) tickHook(now time.Time) (model, tea.Cmd) {
+	if m.onTickCmd == "" || m.state != running || m.done {
+		return m, nil
+	}
+	if !m.lastTickHook.IsZero() && now.Sub(m.lastTickHook) < m.tickHookInterval {
+		return m, nil
+	}
+	m.lastTickHook = now
+	secs := strconv.Itoa(int(m.timeRemaining.Seconds()))
+	if m.dryRun {
+		return m, m.dispatch.dryRun(tickHookChannel, "", m.onTickCmd+" "+secs)
+	}
+	return m, m.dispatch.runHook(tickHookChannel, "", shellCommand(m.onTickCmd, secs))
+}
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestHookStartFailure(t *testing.T) {
+	isolate(t)
+	c := exec.Command(filepath.Join(t.TempDir(), "missing"))
+	msg, ok := runHook("Hook complete", c)().(deliveryErrMsg)
+	if !ok || msg.channel != "Hook complete" {
+		t.Errorf("got %#v, want a failed delivery on Hook complete", msg)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+type iconSet struct {
+	running string
+	paused  string
+	done    string
+	rest    string
+	warning string
+}
+
+var iconSets = map[string]iconSet{
+	"none": {},
+	"emoji": {
+		running: "⏳",
+		paused:  "⏸",
+		done:    "✅",
+		rest:    "☕",
+		warning: "⚠",
+	},
+	"nerd": {
+		running: "\U000F051F", // nf-md-timer_sand
+		paused:  "\U000F03E4", // nf-md-pause
+		done:    "\U000F05E0", // nf-md-check_circle
+		rest:    "\U000F0176", // nf-md-coffee
+		warning: "\U000F0026", // nf-md-alert
+	},
+	"ascii": {
+		running: ">",
+		paused:  "||",
+		done:    "[x]",
+		rest:    "~",
+		warning: "!",
+	},
+}
+
+// iconSet resolves the configured icons. Nerd Font glyphs render as
+// boxes without a patched font, so they fall back to ASCII unless the
+// config says one is installed. Remote mode always uses ASCII.
+func (c config) iconSet() iconSet {
+	if c.icons != "none" && c.isRemote() || c.icons == "nerd" && !c.nerdFonts {
+		return iconSets["ascii"]
+	}
+	return iconSets[c.icons]
+}
+
+func (m model) stateIcon() string {
+	switch {
+	case m.state == inputtingTime:
+		return ""
+	case m.done:
+		return m.icons.done
+	case m.state == paused:
+		return m.icons.paused
+	case m.pomodoro != nil && m.pomodoro.phase != work,
+		m.intervals != nil && m.intervals.kind == intervalRest:
+		return m.icons.rest
+	}
+	return m.icons.running
+}
+
+// withIcon prefixes s with the current state's icon, if any.
+func (m model) withIcon(s string) string {
+	if icon := m.stateIcon(); icon != "" {
+		return icon + " " + s
+	}
+	return s
+}
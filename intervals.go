@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+type intervalKind int
+
+const (
+	intervalWork intervalKind = iota
+	intervalRest
+)
+
+func (k intervalKind) String() string {
+	if k == intervalRest {
+		return "Rest"
+	}
+	return "Work"
+}
+
+// intervals is an interval training plan: rounds of work, each followed
+// by rest except the last.
+type intervals struct {
+	work, rest time.Duration
+	rounds     int
+	round      int // 1-based
+	kind       intervalKind
+}
+
+// parseIntervals reads "work=40s rest=20s rounds=8". Fields may also be
+// separated by commas; rest may be 0 and rounds default to 8.
+func parseIntervals(s string) (intervals, error) {
+	iv := intervals{rounds: 8, round: 1}
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == ',' }) {
+		key, v, ok := strings.Cut(f, "=")
+		if !ok {
+			return iv, fmt.Errorf("%q should look like work=40s rest=20s rounds=8", s)
+		}
+		var err error
+		switch key {
+		case "work":
+			iv.work, err = parseDuration(v)
+		case "rest":
+			iv.rest = 0
+			if v != "0" {
+				iv.rest, err = parseDuration(v)
+			}
+		case "rounds":
+			if iv.rounds, err = strconv.Atoi(v); err == nil && iv.rounds < 1 {
+				err = fmt.Errorf("rounds must be a positive number")
+			}
+		default:
+			return iv, fmt.Errorf("unknown interval setting %q; use work, rest and rounds", key)
+		}
+		if err != nil {
+			return iv, fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	if iv.work == 0 {
+		return iv, fmt.Errorf("%q needs a work=DURATION", s)
+	}
+	return iv, nil
+}
+
+func (iv intervals) duration() time.Duration {
+	if iv.kind == intervalRest {
+		return iv.rest
+	}
+	return iv.work
+}
+
+// next returns the phase after this one, or false after the last round's
+// work.
+func (iv intervals) next() (intervals, bool) {
+	switch {
+	case iv.kind == intervalWork && iv.rest > 0 && iv.round < iv.rounds:
+		iv.kind = intervalRest
+	case iv.round < iv.rounds:
+		iv.round++
+		iv.kind = intervalWork
+	default:
+		return iv, false
+	}
+	return iv, true
+}
+
+// left is the time still planned after the current phase.
+func (iv intervals) left() time.Duration {
+	var d time.Duration
+	for n, ok := iv.next(); ok; n, ok = n.next() {
+		d += n.duration()
+	}
+	return d
+}
+
+func (iv intervals) first() intervals {
+	iv.round, iv.kind = 1, intervalWork
+	return iv
+}
+
+// advanceIntervals starts the next phase after one completes.
+func (m model) advanceIntervals(next intervals) model {
+	m.sessionTotal += m.sittingTime()
+	m.intervals = &next
+	m = m.begin(next.duration())
+	m.phaseChanged = true
+	return m
+}
+
+// intervalsView is the phase line above the timer, e.g.
+// "WORK · round 3 of 8 · then Rest 00:20 · 05:20 to go". Work and rest
+// are coloured apart so they can be told from across the room.
+func (m model) intervalsView() string {
+	iv := m.intervals
+	style := completedStyle
+	if iv.kind == intervalWork {
+		style = overtimeStyle
+	}
+	line := style.Render(strings.ToUpper(iv.kind.String())) + fmt.Sprintf(" · round %d of %d", iv.round, iv.rounds)
+	if n, ok := iv.next(); ok && !m.done {
+		line += lipgloss.NewStyle().Faint(true).Render(fmt.Sprintf(" · then %s %s · %s to go",
+			n.kind, formatDuration(n.duration()), formatDuration(iv.left()+m.timeRemaining)))
+	}
+	return line
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// The event journal records what happened to each timer as it happened,
+// one tab-separated line per event:
+//
+//	time  timer  start|pause|resume|adjust|end|trim|split|relabel|delete  value  label
+//
+// The timer is the time it started counting, which identifies it. A start
+// carries the planned duration and the label, an adjust the change and an
+// end the status. The history is replayed from these, so nothing is lost
+// to aggregation; history.tsv from older versions is still read.
+//
+// Edits made afterwards with `history edit` are events too, applied to a
+// finished timer in order: a trim carries the new end, a split the time
+// the second part starts, which becomes that part's timer, and a relabel
+// the new label. A delete leaves the timer out of the history.
+
+type event struct {
+	at    time.Time
+	timer string
+	kind  string
+	value string
+	label string
+}
+
+func eventsFile() string {
+	return filepath.Join(dataDir(), "events.tsv")
+}
+
+func (e event) String() string {
+	return strings.Join([]string{
+		e.at.Format(time.RFC3339Nano), e.timer, e.kind, e.value, strings.Join(strings.Fields(e.label), " "),
+	}, "\t")
+}
+
+func parseEvent(line string) (event, error) {
+	f := strings.SplitN(line, "\t", 5)
+	if len(f) != 5 {
+		return event{}, fmt.Errorf("expected 5 fields, got %d", len(f))
+	}
+	at, err := time.Parse(time.RFC3339Nano, f[0])
+	return event{at: at, timer: f[1], kind: f[2], value: f[3], label: f[4]}, err
+}
+
+func appendEvents(events ...event) error {
+	if err := os.MkdirAll(dataDir(), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(eventsFile(), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var b strings.Builder
+	for _, e := range events {
+		b.WriteString(e.String() + "\n")
+	}
+	_, err = f.WriteString(b.String())
+	return err
+}
+
+func loadEvents() []event {
+	f, err := os.Open(eventsFile())
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var out []event
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if e, err := parseEvent(sc.Text()); err == nil {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// replayEvents rebuilds the history from the journal. Events are written
+// in the background, so they are put back in time order first. Timers
+// without an end, such as one running when the machine crashed, are left
+// out.
+func replayEvents(events []event) []historyEntry {
+	sort.SliceStable(events, func(i, j int) bool { return events[i].at.Before(events[j].at) })
+	timers := map[string]*replay{}
+	var ended []*replay
+	for _, e := range events {
+		r := timers[e.timer]
+		if e.kind == "start" {
+			planned, _ := time.ParseDuration(e.value)
+			timers[e.timer] = &replay{entry: historyEntry{id: e.timer, start: e.at, planned: planned, label: e.label}}
+			continue
+		}
+		// Edits apply once the timer has ended, and only edits do.
+		if r == nil || r.ended != (e.kind == "trim" || e.kind == "split" || e.kind == "relabel" || e.kind == "delete") {
+			continue
+		}
+		switch e.kind {
+		case "pause":
+			r.pausedAt = e.at
+		case "resume":
+			if !r.pausedAt.IsZero() {
+				r.pauses = append(r.pauses, [2]time.Time{r.pausedAt, e.at})
+				r.pausedAt = time.Time{}
+			}
+		case "adjust":
+			d, _ := time.ParseDuration(e.value)
+			r.entry.planned += d
+		case "end":
+			if !r.pausedAt.IsZero() {
+				r.pauses = append(r.pauses, [2]time.Time{r.pausedAt, e.at})
+			}
+			r.entry.end = e.at
+			r.entry.status = e.value
+			r.ended = true
+			ended = append(ended, r)
+		case "trim":
+			if at, err := time.Parse(time.RFC3339Nano, e.value); err == nil && r.within(at) {
+				r.entry.end = at
+			}
+		case "split":
+			at, err := time.Parse(time.RFC3339Nano, e.value)
+			if err != nil || !r.within(at) || timers[e.value] != nil {
+				continue
+			}
+			second := &replay{entry: r.entry, pauses: r.pauses, ended: true, unplanned: true}
+			second.entry.id, second.entry.start = e.value, at
+			r.entry.end = at
+			timers[e.value] = second
+			ended = append(ended, second)
+		case "relabel":
+			r.entry.label = e.label
+		case "delete":
+			r.deleted = true
+		}
+	}
+	out := make([]historyEntry, 0, len(ended))
+	for _, r := range ended {
+		if !r.deleted {
+			out = append(out, r.settle())
+		}
+	}
+	return out
+}
+
+// replay is a timer as its events have left it so far.
+type replay struct {
+	entry    historyEntry
+	pausedAt time.Time
+	pauses   [][2]time.Time
+	ended    bool
+	deleted  bool
+	// unplanned is the second part of a split, which was never planned
+	// and so takes its own length.
+	unplanned bool
+}
+
+// within reports whether at falls inside the finished timer, where a trim
+// or split can go.
+func (r *replay) within(at time.Time) bool {
+	return at.After(r.entry.start) && at.Before(r.entry.end)
+}
+
+// settle works out the time paused and taken between the entry's start
+// and end, counting only the pauses that fall inside it.
+func (r *replay) settle() historyEntry {
+	e := r.entry
+	e.paused, e.pauses = 0, nil
+	for _, p := range r.pauses {
+		from, to := later(p[0], e.start), earlier(p[1], e.end)
+		if to.After(from) {
+			e.paused += to.Sub(from)
+			e.pauses = append(e.pauses, [2]time.Time{from, to})
+		}
+	}
+	e.actual = (e.end.Sub(e.start) - e.paused).Round(time.Second)
+	e.paused = e.paused.Round(time.Second)
+	if r.unplanned {
+		e.planned = e.actual
+	}
+	return e
+}
+
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func earlier(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// event makes a journal entry for the current timer.
+func (m model) event(kind, value string) event {
+	return event{at: m.clock.Now(), timer: m.journalID, kind: kind, value: value}
+}
+
+// journalEvents makes the journal entries for kind, opening the timer
+// with a start first if it has none yet.
+func (m model) journalEvents(kind, value string) (model, []event) {
+	var events []event
+	if m.journalID == "" {
+		// Dated when counting began, not when the first tick noticed.
+		m.journalID = m.countdown.Started.UTC().Format(time.RFC3339Nano)
+		planned := m.countdown.Duration
+		if kind == "adjust" {
+			// An adjustment before the first tick is already in the
+			// duration; the start carries it from before, as replay adds
+			// the adjust event on top.
+			d, _ := time.ParseDuration(value)
+			planned -= d
+		}
+		events = append(events, event{at: m.countdown.Started, timer: m.journalID, kind: "start", value: planned.String(), label: m.historyLabel()})
+	}
+	if kind != "start" {
+		events = append(events, m.event(kind, value))
+	}
+	return m, events
+}
+
+// journal writes the entries for kind in the background.
+func (m model) journal(kind, value string) (model, tea.Cmd) {
+	m, events := m.journalEvents(kind, value)
+	if len(events) == 0 {
+		return m, nil
+	}
+	return m, func() tea.Msg {
+		_ = appendEvents(events...)
+		return nil
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+type placement struct {
+	h, v lipgloss.Position
+}
+
+// parsePlacement accepts center or a vertical/horizontal pair such as
+// top-left, bottom or right.
+func parsePlacement(s string) (placement, error) {
+	p := placement{h: lipgloss.Center, v: lipgloss.Center}
+	if s == "center" {
+		return p, nil
+	}
+	for _, part := range strings.Split(s, "-") {
+		switch part {
+		case "top":
+			p.v = lipgloss.Top
+		case "bottom":
+			p.v = lipgloss.Bottom
+		case "left":
+			p.h = lipgloss.Left
+		case "right":
+			p.h = lipgloss.Right
+		default:
+			return p, fmt.Errorf("%q is not a position; use center, top, bottom, left, right or a pair like top-left", s)
+		}
+	}
+	return p, nil
+}
+
+// place positions the rendered view on the alternate screen, following
+// resizes. Inline output stays left-aligned with the surrounding shell
+// text instead of being padded out to a screenful of blank lines.
+func (m model) place(content string) string {
+	if !m.altScreen || m.width == 0 || m.height == 0 {
+		return content
+	}
+	return lipgloss.Place(m.width, m.height, m.placement.h, m.placement.v, content)
+}
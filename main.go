@@ -0,0 +1,448 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/term"
+)
+
+// Exit codes let scripts tell what happened to the timer.
+const (
+	exitCompleted = 0
+	exitError     = 1 // the terminal or program failed
+	exitCancelled = 2 // the user quit before the timer completed
+	exitInvalid   = 3 // bad flags, duration or config
+	exitStopped   = 4 // watch: the command was stopped with q
+)
+
+// exitDelay is an optionally valued flag: --exit-on-complete quits right
+// after completion, --exit-on-complete=10s lingers on the done screen first.
+type exitDelay struct {
+	enabled bool
+	delay   time.Duration
+}
+
+func (e *exitDelay) String() string {
+	if !e.enabled {
+		return ""
+	}
+	return e.delay.String()
+}
+
+func (e *exitDelay) Set(s string) error {
+	switch s {
+	case "true":
+		e.enabled = true
+		return nil
+	case "false":
+		e.enabled = false
+		return nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return fmt.Errorf("invalid delay %q", s)
+	}
+	e.enabled, e.delay = true, d
+	return nil
+}
+
+func (e *exitDelay) IsBoolFlag() bool { return true }
+
+// optionalValue is a flag that may be given bare, meaning "use the
+// configured value", or with an explicit value.
+type optionalValue struct {
+	set   bool
+	value string
+}
+
+func (o *optionalValue) String() string { return o.value }
+
+func (o *optionalValue) Set(s string) error {
+	o.set = s != "false"
+	if s != "true" && s != "false" {
+		o.value = s
+	}
+	return nil
+}
+
+func (o *optionalValue) IsBoolFlag() bool { return true }
+
+// parseArgs parses flags wherever they appear, so that both
+// "progress-timer 10 --exit-on-complete" and the reverse work, and returns
+// the positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// subcommand is a command besides running a timer.
+type subcommand struct {
+	name     string
+	synopsis string
+	run      func(args []string) int
+}
+
+func subcommands() []subcommand {
+	return []subcommand{
+		{"config", "<init|check> [path]", runConfigCommand},
+		{"schedule", "[run]", runScheduleCommand},
+		{"plan", "[import FILE | week]", runPlanCommand},
+		{"sessions", "", runSessionsCommand},
+		{"history", "[browse|edit]", runHistoryCommand},
+		{"stats", "", runStatsCommand},
+		{"review", "", runReviewCommand},
+		{"prune", "[KEEP]", runPruneCommand},
+		{"estimates", "", runEstimatesCommand},
+		{"backup", "[FILE|-]", runBackupCommand},
+		{"restore", "FILE|-", runRestoreCommand},
+		{"doctor", "[SECONDS]", runDoctorCommand},
+		{"watch", "[--expect 10m] -- COMMAND [ARGS...]", runWatchCommand},
+		{"tail", "[--pattern REGEX] FILE", runTailCommand},
+	}
+}
+
+// usageLine is how to call the subcommand name.
+func usageLine(name string) string {
+	for _, c := range subcommands() {
+		if c.name == name {
+			return strings.TrimSpace("usage: " + appName + " " + name + " " + c.synopsis)
+		}
+	}
+	return "usage: " + appName + " " + name
+}
+
+// usage reports a subcommand called the wrong way and returns the exit
+// status for it.
+func usage(name string) int {
+	fmt.Fprintln(os.Stderr, usageLine(name))
+	return exitInvalid
+}
+
+func main() {
+	if len(os.Args) > 1 {
+		for _, c := range subcommands() {
+			if c.name == os.Args[1] {
+				os.Exit(c.run(os.Args[2:]))
+			}
+		}
+	}
+
+	flag.CommandLine.Init(appName, flag.ContinueOnError)
+
+	var (
+		exitOnComplete exitDelay
+		duration       string
+	)
+	flag.StringVar(&duration, "duration", "", "start this duration right away instead of showing the input screen (e.g. 25m, 1:30:00, until 14:30)")
+	flag.StringVar(&duration, "d", "", "shorthand for --duration")
+	warmup := flag.Duration("warmup", -1, "get-ready countdown before the timer starts, e.g. 3s (default from config)")
+	label := flag.String("label", "", "label for the timer, shown above it and in notifications, the history and hooks (Tab edits it on the input screen)")
+	noNotify := flag.Bool("no-notify", false, "don't show a desktop notification on completion (overrides notifications)")
+	noAltScreen := flag.Bool("no-alt-screen", false, "draw inline instead of on the alternate screen (overrides alt_screen)")
+	prefill := flag.String("prefill", "", "text to pre-type on the input screen, ready to confirm with Enter")
+	overtime := flag.Bool("overtime", false, "keep counting past zero and show the overrun")
+	onComplete := flag.String("on-complete", "", "command to run when the timer completes, with the timer in TIMER_* environment variables (overrides hooks.on_complete)")
+	onTickCmd := flag.String("on-tick-cmd", "", "command to run while the timer runs, given the remaining seconds as an argument")
+	tickInterval := flag.Duration("on-tick-interval", 0, "minimum time between --on-tick-cmd runs (default from config, 10s)")
+	var fromCalendar, pomodoroFlag, intervalsFlag optionalValue
+	flag.Var(&fromCalendar, "from-calendar", "count down to the end of the current or next event in an .ics file or CalDAV URL (default from config)")
+	flag.Var(&intervalsFlag, "intervals", "interval training: rounds of work and rest, optionally as =\"work=40s rest=20s rounds=8\" (default from config)")
+	flag.Var(&pomodoroFlag, "pomodoro", "cycle work and breaks automatically, optionally with a pattern such as =50/10/30x3 (default from config, 25/5/15x4)")
+	estimate := flag.Duration("estimate", 0, "record how long the labelled task should take in total (e.g. 3h); the estimates command compares it with the time spent")
+	project := flag.String("project", "", "apply the settings in projects/NAME.ini next to the config file on top of it")
+	session := flag.String("session", "", "name of a session to resume; time spent is added to its running total")
+	themeFlag := flag.String("theme", "", "color theme: "+strings.Join(schemeNames(), ", ")+" (default from config)")
+	remote := flag.Bool("remote", false, "low-bandwidth mode for slow or SSH connections (default from config: auto-detects SSH)")
+	dryRunFlag := flag.Bool("dry-run", false, "log the notifications, calendar pushes and hooks that would be sent to delivery.log instead of sending them")
+	headlessFlag := flag.Bool("headless", false, "run without the TUI, printing a progress line every 10% (the default when output is not a terminal and a duration is given)")
+	quiet := flag.Bool("quiet", false, "with --headless, print nothing")
+	output := flag.String("output", "text", "text, or json for newline-delimited events on stdout for status bars and scripts (implies --headless)")
+	var sinkOpts sinkFlags
+	flag.StringVar(&sinkOpts.progressFile, "progress-file", "", "keep the latest progress event in this file as JSON")
+	flag.StringVar(&sinkOpts.socket, "status-socket", "", "stream progress events as JSON lines to clients of this Unix socket")
+	flag.StringVar(&sinkOpts.web, "web-overlay", "", "serve a browser overlay of the timer on this address (e.g. localhost:8765), with the events on /events and /status")
+	watchPid := flag.Int("watch-pid", 0, "alert when the running process with this PID exits, timing it until then")
+	stdin := flag.Bool("stdin", false, "read commands (start 25m label, pause, resume, add 5m, stop, quit) from standard input")
+	flag.Var(&exitOnComplete, "exit-on-complete", "quit after the timer completes, optionally after a delay (e.g. =10s)")
+	flag.Usage = func() {
+		out := flag.CommandLine.Output()
+		fmt.Fprintf(out, "usage: %s [flags] [duration]\n", appName)
+		for _, c := range subcommands() {
+			fmt.Fprintf(out, "       %s\n", strings.TrimPrefix(usageLine(c.name), "usage: "))
+		}
+		fmt.Fprintln(out)
+		flag.PrintDefaults()
+		fmt.Fprintln(flag.CommandLine.Output(), "\nExit status: 0 completed, 1 error, 2 cancelled, 3 invalid input or config,\n4 watched command stopped; otherwise watch exits with the command's status.")
+	}
+	args, err := parseArgs(flag.CommandLine, os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(exitCompleted)
+	}
+	if err != nil {
+		os.Exit(exitInvalid)
+	}
+
+	var overlays []string
+	if *project != "" {
+		if strings.ContainsAny(*project, `/\`) {
+			fmt.Fprintf(os.Stderr, "invalid project name %q\n", *project)
+			os.Exit(exitInvalid)
+		}
+		overlays = append(overlays, projectFile(*project))
+	}
+	cfg, err := loadConfig(configFile(), overlays...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitInvalid)
+	}
+
+	if *themeFlag != "" {
+		if _, ok := findScheme(*themeFlag); !ok {
+			fmt.Fprintf(os.Stderr, "--theme must be one of %s\n", strings.Join(schemeNames(), ", "))
+			os.Exit(exitInvalid)
+		}
+		cfg.scheme = *themeFlag
+	}
+	if *remote {
+		cfg.remote = "on"
+	}
+	if *noAltScreen {
+		cfg.altScreen = "off"
+	}
+	if *noNotify {
+		cfg.notifications = false
+	}
+	cfg.dryRun = *dryRunFlag
+	if *onComplete != "" {
+		cfg.eventHooks[eventComplete] = *onComplete
+	}
+
+	watching := false
+	flag.Visit(func(f *flag.Flag) { watching = watching || f.Name == "watch-pid" })
+	if watching {
+		// kill(2) reads 0 and negative pids as process groups, and -1 as
+		// every process, so none of them would ever be gone.
+		if *watchPid <= 0 {
+			fmt.Fprintf(os.Stderr, "--watch-pid needs a process ID above 0, got %d\n", *watchPid)
+			os.Exit(exitInvalid)
+		}
+		if duration != "" || len(args) > 0 {
+			fmt.Fprintln(os.Stderr, "--watch-pid times the process; drop the duration")
+			os.Exit(exitInvalid)
+		}
+		os.Exit(watchPID(cfg, *watchPid))
+	}
+
+	opts := cfg.programOptions()
+	if *stdin {
+		// Keys come from the terminal while stdin carries commands.
+		opts = append(opts, tea.WithInputTTY())
+	}
+
+	if *warmup >= 0 {
+		cfg.warmup = *warmup
+	}
+	switch *output {
+	case "text":
+	case "json":
+		*headlessFlag = true
+	default:
+		fmt.Fprintf(os.Stderr, "--output must be text or json, not %q\n", *output)
+		os.Exit(exitInvalid)
+	}
+	headless := *headlessFlag || !*stdin && !term.IsTerminal(os.Stdout.Fd())
+	if headless {
+		// There is no screen to count a warm-up on.
+		cfg.warmup = 0
+	}
+
+	if _, err := pruneHistory(cfg.retention, time.Now()); err != nil {
+		fmt.Fprintln(os.Stderr, "pruning the history:", err)
+	}
+
+	setup := timerSetup{project: *project, overtime: *overtime, onTickCmd: *onTickCmd, dispatch: newDispatcher(cfg.rateLimits)}
+	if mode, ok := loadReadout(*project); ok {
+		setup.readoutMode = mode
+	}
+	if *tickInterval != 0 {
+		setup.tickInterval = max(*tickInterval, minTickHookInterval)
+	}
+	m := setup.newTimer(cfg)
+	m.exitOnComplete = exitOnComplete.enabled
+	m.exitDelay = exitOnComplete.delay
+	if *prefill != "" {
+		m.textInput.SetValue(*prefill)
+	}
+	if pomodoroFlag.set {
+		if duration != "" || len(args) > 0 || fromCalendar.set {
+			fmt.Fprintln(os.Stderr, "--pomodoro sets its own durations; drop the duration or --from-calendar")
+			os.Exit(exitInvalid)
+		}
+		p := cfg.pomodoro
+		if pomodoroFlag.value != "" {
+			if p, err = parsePomodoro(pomodoroFlag.value); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(exitInvalid)
+			}
+		}
+		m.pomodoro = &p
+		m = m.begin(p.duration()).withWarmup()
+	} else if intervalsFlag.set {
+		if duration != "" || len(args) > 0 || fromCalendar.set {
+			fmt.Fprintln(os.Stderr, "--intervals sets its own durations; drop the duration or --from-calendar")
+			os.Exit(exitInvalid)
+		}
+		iv := cfg.intervals
+		if intervalsFlag.value != "" {
+			if iv, err = parseIntervals(intervalsFlag.value); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(exitInvalid)
+			}
+		}
+		m.intervals = &iv
+		m = m.begin(iv.duration()).withWarmup()
+	} else if fromCalendar.set {
+		path := fromCalendar.value
+		if path == "" {
+			path = cfg.calendarFile
+		}
+		if path == "" {
+			path = cfg.caldav.url
+		}
+		if path == "" {
+			fmt.Fprintln(os.Stderr, "--from-calendar needs a path, calendar.file or caldav.url")
+			os.Exit(exitInvalid)
+		}
+		now := time.Now()
+		e, err := eventFromCalendar(path, cfg.caldav, now)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitInvalid)
+		}
+		m = m.begin(e.end.Sub(now).Round(time.Second))
+		m.label = e.summary
+	} else if duration != "" || len(args) > 0 {
+		if duration != "" && len(args) > 0 {
+			fmt.Fprintln(os.Stderr, "give the duration either with --duration or as an argument, not both")
+			os.Exit(exitInvalid)
+		}
+		input := duration
+		if input == "" {
+			input = strings.Join(args, " ")
+		}
+		check := input
+		if p, ok := cfg.presets.lookup(input); ok {
+			check = p
+		}
+		if _, ok := cfg.templates.lookup(input); ok {
+			check = "1" // its blanks are asked for on the screen
+		}
+		if _, _, err := parseTimerInput(check, time.Now()); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid duration %q: %v\n", input, err)
+			os.Exit(exitInvalid)
+		}
+		// No program is running yet to carry out what start asks for,
+		// saving the input to the recents, so do it here.
+		started, cmd := m.start(input)
+		m = started.(model)
+		runHeadlessCmd(cmd)
+	}
+
+	if *label != "" {
+		m.label = *label
+		m.labelInput.SetValue(*label)
+	}
+	if *session != "" {
+		m.session = *session
+		m.sessionTotal = loadSessions()[*session]
+		if m.label == "" {
+			m.label = *session
+		}
+	}
+
+	if *estimate > 0 {
+		if m.label == "" {
+			fmt.Fprintln(os.Stderr, "--estimate needs a --label or --session to attach to")
+			os.Exit(exitInvalid)
+		}
+		if err := setEstimate(m.label, *estimate); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitError)
+		}
+		m.estimates[m.label] = estimate.Round(time.Second)
+	}
+
+	if *headlessFlag && m.state == inputtingTime && !*stdin {
+		fmt.Fprintln(os.Stderr, "--headless needs a duration, --pomodoro, --intervals, --from-calendar or --stdin")
+		os.Exit(exitInvalid)
+	}
+	sinkOpts.json = *output == "json"
+	if setup.sinks, err = sinkOpts.open(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitInvalid)
+	}
+	m.sinks = setup.sinks
+
+	var timers []model
+	if headless && (m.state != inputtingTime || *stdin) {
+		var commands chan string
+		if *stdin {
+			commands = make(chan string)
+			go func() {
+				readCommands(os.Stdin, func(msg tea.Msg) { commands <- string(msg.(commandMsg)) })
+				close(commands)
+			}()
+		}
+		timers = []model{runHeadless(m, *quiet || sinkOpts.json, commands)}
+	} else {
+		p := tea.NewProgram(newTimerList(cfg, setup, m), opts...)
+		if *stdin {
+			go readCommands(os.Stdin, p.Send)
+		}
+		final, err := p.Run()
+		os.Stdout.WriteString(cfg.reporter.clearSequence())
+		if err != nil {
+			fmt.Printf("Error running program: %v", err)
+			os.Exit(exitError)
+		}
+		timers = final.(timerList).timers
+	}
+	for _, t := range timers {
+		if t.state != inputtingTime && !t.done {
+			t.publish("cancelled")
+		}
+		logUnfinished(t)
+	}
+	m.sinks.close()
+	if cfg.dryRun {
+		fmt.Fprintf(os.Stderr, "Dry run: nothing was sent; see %s\n", deliveryLogFile())
+	}
+	// The first timer is the one the flags started and owns the session.
+	fm := timers[0]
+	if fm.session != "" {
+		if err := addSessionTime(fm.session, fm.sessionTotal-m.sessionTotal+fm.sittingTime()); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		} else {
+			fmt.Printf("%s: %s in total\n", fm.session, formatDuration(fm.sessionTotal+fm.sittingTime()))
+		}
+	}
+	if !fm.completed {
+		os.Exit(exitCancelled)
+	}
+}
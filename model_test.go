@@ -0,0 +1,370 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// fakeClock is a clock the test moves by hand.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) advance(d time.Duration) time.Time {
+	c.now = c.now.Add(d)
+	return c.now
+}
+
+// isolate points every data directory at a temporary one, so tests never
+// read or write the user's history, journal or config.
+func isolate(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	for _, env := range []string{"XDG_CONFIG_HOME", "XDG_DATA_HOME", "XDG_STATE_HOME"} {
+		t.Setenv(env, dir)
+	}
+}
+
+// startedModel is a model that has just started counting down input.
+func startedModel(t *testing.T, input string) (model, *fakeClock) {
+	t.Helper()
+	isolate(t)
+	clk := &fakeClock{now: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)}
+	m := initialModel(defaultConfig())
+	m.clock = clk
+	next, _ := m.start(input)
+	m = next.(model)
+	if m.state != running {
+		t.Fatalf("start(%q): state %v, want running (error %q)", input, m.state, m.err)
+	}
+	return m, clk
+}
+
+func TestTickCompletes(t *testing.T) {
+	tests := []struct {
+		name     string
+		rounding string
+		elapsed  time.Duration
+		left     time.Duration
+		done     bool
+	}{
+		{"halfway", "nearest", 30 * time.Second, 30 * time.Second, false},
+		{"rounds to zero early", "nearest", 59*time.Second + 600*time.Millisecond, 0, false},
+		{"rounds up", "up", 59*time.Second + 600*time.Millisecond, time.Second, false},
+		{"at the deadline", "nearest", time.Minute, 0, true},
+		{"late tick", "down", 75 * time.Second, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, clk := startedModel(t, "1")
+			m.rounding = tt.rounding
+			m, _ = m.tick(clk.advance(tt.elapsed))
+			if m.timeRemaining != tt.left || m.done != tt.done {
+				t.Errorf("after %s: left %s, done %v; want %s, %v", tt.elapsed, m.timeRemaining, m.done, tt.left, tt.done)
+			}
+		})
+	}
+}
+
+func TestPauseResume(t *testing.T) {
+	m, clk := startedModel(t, "1")
+	m, _ = m.tick(clk.advance(10 * time.Second))
+
+	next, _ := m.pauseOrResume()
+	m = next.(model)
+	m, _ = m.tick(clk.advance(5 * time.Minute))
+	if m.state != paused || m.timeRemaining != 50*time.Second {
+		t.Fatalf("paused: state %v, left %s; want paused, 50s", m.state, m.timeRemaining)
+	}
+
+	next, _ = m.pauseOrResume()
+	m = next.(model)
+	m, _ = m.tick(clk.advance(20 * time.Second))
+	if m.state != running || m.timeRemaining != 30*time.Second {
+		t.Fatalf("resumed: state %v, left %s; want running, 30s", m.state, m.timeRemaining)
+	}
+	if m.countdown.Pauses != 1 || m.pausedTotal() != 5*time.Minute {
+		t.Errorf("pauses %d for %s, want 1 for 5m0s", m.countdown.Pauses, m.pausedTotal())
+	}
+
+	m, _ = m.tick(clk.advance(30 * time.Second))
+	if !m.done {
+		t.Error("not done at the moved deadline")
+	}
+}
+
+func TestAdjust(t *testing.T) {
+	tests := []struct {
+		name     string
+		delta    time.Duration
+		duration time.Duration
+		left     time.Duration
+		done     bool
+	}{
+		{"add", time.Minute, 2 * time.Minute, 100 * time.Second, false},
+		{"take off", -30 * time.Second, 30 * time.Second, 10 * time.Second, false},
+		{"take off more than is left", -10 * time.Minute, 20 * time.Second, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, clk := startedModel(t, "1")
+			m, _ = m.tick(clk.advance(20 * time.Second))
+			next, _ := m.adjust(tt.delta)
+			m = next.(model)
+			if m.countdown.Duration != tt.duration || m.timeRemaining != tt.left || m.done != tt.done {
+				t.Fatalf("duration %s, left %s, done %v; want %s, %s, %v",
+					m.countdown.Duration, m.timeRemaining, m.done, tt.duration, tt.left, tt.done)
+			}
+			if !tt.done {
+				m, _ = m.tick(clk.advance(tt.left))
+				if !m.done {
+					t.Error("not done at the adjusted deadline")
+				}
+			}
+		})
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "00:00"},
+		{time.Second, "00:01"},
+		{1500 * time.Millisecond, "00:02"},
+		{25 * time.Minute, "25:00"},
+		{59*time.Minute + 59*time.Second, "59:59"},
+		{time.Hour, "01:00:00"},
+		{90*time.Minute + 5*time.Second, "01:30:05"},
+		{100 * time.Hour, "100:00:00"},
+	}
+	for _, tt := range tests {
+		if got := formatDuration(tt.d); got != tt.want {
+			t.Errorf("formatDuration(%s) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestQuitGrace(t *testing.T) {
+	esc := tea.KeyMsg{Type: tea.KeyEsc}
+	tests := []struct {
+		name  string
+		wait  time.Duration
+		key   tea.KeyMsg
+		quits bool
+	}{
+		{"Esc again at once", time.Second, esc, true},
+		{"Esc again too late", quitGrace, esc, false},
+		{"y after the grace period", time.Minute, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")}, true},
+		{"n", 0, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, clk := startedModel(t, "1")
+			next, cmd := m.Update(esc)
+			m = next.(model)
+			if cmd != nil || !m.confirming {
+				t.Fatal("the first Esc quit without asking")
+			}
+			clk.advance(tt.wait)
+			next, cmd = m.Update(tt.key)
+			m = next.(model)
+			quits := cmd != nil && cmd() == tea.Quit()
+			if quits != tt.quits || !quits && m.confirming {
+				t.Errorf("quits %v, still asking %v; want quits %v", quits, m.confirming, tt.quits)
+			}
+		})
+	}
+}
+
+func TestCompletedAfterNewTimer(t *testing.T) {
+	m, clk := startedModel(t, "1")
+	m, _ = m.tick(clk.advance(time.Minute))
+	next, _ := m.summaryKey("n")
+	m = next.(model)
+	if m.state != inputtingTime || !m.completed {
+		t.Fatalf("after n: state %v, completed %v; want the input screen, completed", m.state, m.completed)
+	}
+	next, _ = m.start("1")
+	if m = next.(model); m.completed {
+		t.Error("a new timer still counts as completed")
+	}
+}
+
+func TestQuitFromFinderAsks(t *testing.T) {
+	for _, name := range []string{"Ctrl+C in the palette", "Quit action"} {
+		t.Run(name, func(t *testing.T) {
+			m, _ := startedModel(t, "1")
+			m.confirmQuit = true
+			next, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlK})
+			m = next.(model)
+			var cmd tea.Cmd
+			if name == "Quit action" {
+				pick := m.finder.pick
+				m.finder = nil
+				next, cmd = pick(m, "Quit")
+			} else {
+				next, cmd = m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+			}
+			m = next.(model)
+			if cmd != nil || !m.confirming || m.finder != nil {
+				t.Errorf("quit without asking: cmd %v, confirming %v, finder open %v", cmd != nil, m.confirming, m.finder != nil)
+			}
+		})
+	}
+}
+
+func TestPauseWhenDone(t *testing.T) {
+	for _, overtime := range []bool{false, true} {
+		m, clk := startedModel(t, "1")
+		m.overtime = overtime
+		m, _ = m.tick(clk.advance(time.Minute))
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeySpace})
+		if m = next.(model); (m.state == paused) != overtime {
+			t.Errorf("overtime %v: Space on a finished timer left it %v", overtime, m.state)
+		}
+	}
+}
+
+func TestWarmupBell(t *testing.T) {
+	for _, bell := range []bool{false, true} {
+		m, _ := startedModel(t, "1")
+		m.bell = bell
+		m.warmupLeft = 3 * time.Second
+		if _, cmd := m.warmupTick(); (cmd != nil) != bell {
+			t.Errorf("bell %v: warm-up rang %v", bell, cmd != nil)
+		}
+	}
+}
+
+func TestPlanBar(t *testing.T) {
+	isolate(t)
+	clk := &fakeClock{now: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)}
+	m := initialModel(defaultConfig())
+	m.clock = clk
+	m.theme.asciiBar, m.theme.highContrast = true, true
+	iv, err := parseIntervals("work=40s rest=20s rounds=3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	iv.round, iv.kind = 2, intervalRest
+	m.intervals = &iv
+	m = m.begin(iv.duration())
+	m, _ = m.tick(clk.advance(10 * time.Second))
+
+	segs, current := m.planSegments()
+	if len(segs) != 5 || current != 3 || !segs[current].rest {
+		t.Fatalf("%d segments, at %d; want 5, at the second rest (3)", len(segs), current)
+	}
+	bar := ansi.Strip(m.planBarView())
+	width := m.barWidth()
+	// 40+20+40 seconds done and 10 into the rest, of 160.
+	pos := width * 110 / 160
+	if len(bar) != width || strings.Index(bar, "|") != pos {
+		t.Fatalf("plan bar %q: %d wide with the marker at %d, want %d wide at %d", bar, len(bar), strings.Index(bar, "|"), width, pos)
+	}
+	if !strings.Contains(bar[:pos], "#") || !strings.Contains(bar[:pos], "=") || strings.Trim(bar[pos+1:], "-") != "" {
+		t.Errorf("plan bar %q: want work and rest filled before the marker, empty after", bar)
+	}
+
+	m.intervals = nil
+	p := pomodoro{work: 25 * time.Minute, short: 5 * time.Minute, long: 15 * time.Minute, cycles: 4, phase: shortBreak, completed: 5}
+	m.pomodoro = &p
+	if segs, current := m.planSegments(); len(segs) != 8 || current != 1 || segs[7].name != "Long break" {
+		t.Errorf("pomodoro: %d segments, at %d; want 8 ending in a long break, at 1", len(segs), current)
+	}
+
+	m.pomodoro = nil
+	if bar := m.planBarView(); bar != "" {
+		t.Errorf("plain timer has a plan bar %q", bar)
+	}
+}
+
+func TestBreakTips(t *testing.T) {
+	tips, err := parseBreakTips("Stretch 2m, Drink some water, , Walk 5")
+	if err != nil || len(tips) != 3 || tips[0] != (breakTip{"Stretch", 2 * time.Minute}) || tips[2].text != "Walk 5" {
+		t.Fatalf("parseBreakTips = %+v, %v", tips, err)
+	}
+
+	m, clk := startedModel(t, "5")
+	m.breakTips = tips
+	if _, _, ok := m.breakTip(); ok {
+		t.Error("tip on a plain timer")
+	}
+	p := pomodoro{work: 25 * time.Minute, short: 5 * time.Minute, long: 15 * time.Minute, cycles: 4, phase: shortBreak, completed: 1}
+	m.pomodoro = &p
+	m, _ = m.tick(clk.advance(90 * time.Second))
+	if tip, left, _ := m.breakTip(); tip.text != "Stretch" || left != 30*time.Second {
+		t.Errorf("first break, 1m30s in: %q with %s left, want Stretch with 30s", tip.text, left)
+	}
+	m, _ = m.tick(clk.advance(time.Minute))
+	if tip, _, _ := m.breakTip(); tip.text != "Drink some water" {
+		t.Errorf("first break after Stretch: %q, want Drink some water", tip.text)
+	}
+	p.completed = 2
+	if tip, _, _ := m.breakTip(); tip.text != "Drink some water" {
+		t.Errorf("second break: %q, want Drink some water", tip.text)
+	}
+}
+
+func TestTemplateChain(t *testing.T) {
+	tpl, err := parseTemplate("meeting: Intro 5m, {n}x Item {i} {m}m, Wrap-up 5m")
+	if err != nil || !slices.Equal(tpl.vars, []string{"n", "m"}) {
+		t.Fatalf("parseTemplate = %+v, %v", tpl, err)
+	}
+	for _, bad := range []string{"meeting", "meeting: Intro", "meeting: Intro soon", "meeting: 0x Item 5m"} {
+		if _, err := parseTemplate(bad); err == nil {
+			t.Errorf("parseTemplate(%q) accepted", bad)
+		}
+	}
+	if _, err := tpl.expand(map[string]string{"n": "lots", "m": "10"}); err == nil {
+		t.Error("expand accepted a count of lots")
+	}
+
+	m, clk := startedModel(t, "5")
+	m.templates = templates{tpl}
+	m = m.reset()
+	next, _ := m.startAs("meeting", "")
+	m = next.(model)
+	if m.filling == nil {
+		t.Fatal("no prompt for the blanks")
+	}
+	for _, v := range []string{"2", "10"} {
+		m.filling.input.SetValue(v)
+		next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m = next.(model)
+	}
+	if m.filling != nil || m.chain == nil {
+		t.Fatal("chain not started after the last blank")
+	}
+	var labels []string
+	for _, s := range m.chain.steps {
+		labels = append(labels, s.label)
+	}
+	if want := []string{"Intro", "Item 1", "Item 2", "Wrap-up"}; !slices.Equal(labels, want) {
+		t.Fatalf("steps = %q, want %q", labels, want)
+	}
+	if m.label != "Intro" || m.countdown.Duration != 5*time.Minute {
+		t.Errorf("first step %q %s, want Intro 5m", m.label, m.countdown.Duration)
+	}
+	if got := ansi.Strip(m.chainView()); got != "Step 1 of 4 · then Item 1 10:00 · 30:00 to go" {
+		t.Errorf("chainView = %q", got)
+	}
+
+	m, _ = m.tick(clk.advance(5 * time.Minute))
+	if m.done || m.chain.at != 1 || m.label != "Item 1" || m.countdown.Duration != 10*time.Minute {
+		t.Errorf("after the intro: done %v, step %d %q %s", m.done, m.chain.at, m.label, m.countdown.Duration)
+	}
+	if m.phaseName() != "Item 1" {
+		t.Errorf("phaseName = %q, want Item 1", m.phaseName())
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// notifier shows a desktop notification. Each platform provides one in
+// notify_<os>.go.
+type notifier interface {
+	notify(title, body string) error
+}
+
+const notifyTimeout = 5 * time.Second
+
+// notifier returns nil when notifications are off, and over SSH, where
+// they would pop up on the remote machine.
+func (c config) notifier() notifier {
+	if !c.notifications || c.isRemote() {
+		return nil
+	}
+	return newNotifier()
+}
+
+// desktopNotify sends a notification about what in the background when
+// enabled.
+func (m model) desktopNotify(what, body string) tea.Cmd {
+	if m.notifier == nil {
+		return nil
+	}
+	n, title := m.notifier, appName
+	if m.label != "" {
+		title = m.label
+	}
+	key := m.deliveryKey(what)
+	if m.dryRun {
+		return m.dispatch.dryRun("Desktop notification", key, title+": "+body)
+	}
+	return m.dispatch.deliver("Desktop notification", key, notifyTimeout, func() error { return n.notify(title, body) })
+}
+
+// completionMessage describes what just finished, and for pomodoros what
+// comes next.
+func (m model) completionMessage() string {
+	if m.pomodoro != nil {
+		next := m.pomodoro.next()
+		return fmt.Sprintf("%s is over. %s: %s.", m.pomodoro.phase, next.phase, formatDuration(next.duration()))
+	}
+	if m.intervals != nil {
+		if next, ok := m.intervals.next(); ok {
+			return fmt.Sprintf("%s is over. %s: %s, round %d of %d.", m.intervals.kind, next.kind,
+				formatDuration(next.duration()), next.round, next.rounds)
+		}
+		return fmt.Sprintf("Intervals done: %d rounds.", m.intervals.rounds)
+	}
+	return fmt.Sprintf("Your %s timer is done.", formatDuration(m.countdown.Duration))
+}
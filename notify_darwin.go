@@ -0,0 +1,20 @@
+package main
+
+import "os/exec"
+
+// osaNotifier goes through AppleScript; title and body are passed as
+// arguments so they need no escaping.
+type osaNotifier struct{}
+
+const notifyCommand = "osascript"
+
+func newNotifier() notifier { return osaNotifier{} }
+
+func (osaNotifier) notify(title, body string) error {
+	return exec.Command(notifyCommand,
+		"-e", "on run argv",
+		"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+		"-e", "end run",
+		title, body,
+	).Run()
+}
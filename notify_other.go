@@ -0,0 +1,17 @@
+//go:build !darwin && !windows
+
+package main
+
+import "os/exec"
+
+// notifySend uses libnotify's notify-send, available on most Linux and
+// BSD desktops.
+type notifySend struct{}
+
+const notifyCommand = "notify-send"
+
+func newNotifier() notifier { return notifySend{} }
+
+func (notifySend) notify(title, body string) error {
+	return exec.Command(notifyCommand, "--app-name="+appName, title, body).Run()
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+)
+
+// toastScript raises a toast through the WinRT API from PowerShell. The
+// text comes in through the environment to avoid quoting it, and the
+// toast is attributed to PowerShell, whose app ID is always registered.
+const toastScript = `
+[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$xml = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$text = $xml.GetElementsByTagName('text')
+$text.Item(0).AppendChild($xml.CreateTextNode($env:PT_TITLE)) > $null
+$text.Item(1).AppendChild($xml.CreateTextNode($env:PT_BODY)) > $null
+$app = '{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe'
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier($app).Show([Windows.UI.Notifications.ToastNotification]::new($xml))
+`
+
+type toastNotifier struct{}
+
+const notifyCommand = "powershell"
+
+func newNotifier() notifier { return toastNotifier{} }
+
+func (toastNotifier) notify(title, body string) error {
+	cmd := exec.Command(notifyCommand, "-NoProfile", "-NonInteractive", "-Command", toastScript)
+	cmd.Env = append(os.Environ(), "PT_TITLE="+title, "PT_BODY="+body)
+	return cmd.Run()
+}
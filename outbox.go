@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Calendar pushes that fail are queued in the outbox, one tab-separated
+// line each:
+//
+//	attempts  next-retry  start  end  uid  summary
+//
+// The uid is the event's, the same on every attempt; lines from before
+// it was kept have none and get one when first read. The queue is retried in the background when the program starts, backing
+// off from a minute to six hours between attempts.
+
+const maxRetryDelay = 6 * time.Hour
+
+var outboxMu sync.Mutex
+
+type pendingPush struct {
+	attempts int
+	next     time.Time
+	start    time.Time
+	end      time.Time
+	uid      string
+	summary  string
+}
+
+func outboxFile() string {
+	return filepath.Join(stateDir(), "outbox.tsv")
+}
+
+func (p pendingPush) String() string {
+	return strings.Join([]string{
+		strconv.Itoa(p.attempts), p.next.Format(time.RFC3339), p.start.Format(time.RFC3339),
+		p.end.Format(time.RFC3339), p.uid, strings.Join(strings.Fields(p.summary), " "),
+	}, "\t")
+}
+
+func parsePendingPush(line string) (pendingPush, error) {
+	f := strings.SplitN(line, "\t", 6)
+	switch len(f) {
+	case 5:
+		f = append(f[:4], newEventUID(), f[4])
+	case 6:
+	default:
+		return pendingPush{}, fmt.Errorf("expected 6 fields, got %d", len(f))
+	}
+	p := pendingPush{uid: f[4], summary: f[5]}
+	var err error
+	if p.attempts, err = strconv.Atoi(f[0]); err != nil {
+		return p, err
+	}
+	for i, t := range []*time.Time{&p.next, &p.start, &p.end} {
+		if *t, err = time.Parse(time.RFC3339, f[1+i]); err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
+// retryDelay doubles from a minute with each failed attempt.
+func retryDelay(attempts int) time.Duration {
+	return min(time.Minute<<min(attempts, 10), maxRetryDelay)
+}
+
+func loadOutbox() []pendingPush {
+	f, err := os.Open(outboxFile())
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var out []pendingPush
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if p, err := parsePendingPush(sc.Text()); err == nil {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func saveOutbox(queue []pendingPush) error {
+	if len(queue) == 0 {
+		if err := os.Remove(outboxFile()); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(stateDir(), 0o755); err != nil {
+		return err
+	}
+	var b strings.Builder
+	for _, p := range queue {
+		b.WriteString(p.String() + "\n")
+	}
+	// Write and rename, so quitting mid-flush can't truncate the queue.
+	tmp := outboxFile() + ".tmp"
+	if err := os.WriteFile(tmp, []byte(b.String()), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, outboxFile())
+}
+
+func queuePush(p pendingPush) error {
+	outboxMu.Lock()
+	defer outboxMu.Unlock()
+	return saveOutbox(append(loadOutbox(), p))
+}
+
+// flushOutbox sends the queued pushes that are due and keeps the rest.
+func flushOutbox(c caldavClient, now time.Time) error {
+	outboxMu.Lock()
+	defer outboxMu.Unlock()
+	var left []pendingPush
+	for _, p := range loadOutbox() {
+		if now.Before(p.next) {
+			left = append(left, p)
+			continue
+		}
+		if err := c.putSession(p.uid, p.summary, p.start, p.end); err != nil {
+			p.next = now.Add(retryDelay(p.attempts))
+			p.attempts++
+			left = append(left, p)
+			continue
+		}
+		_ = logDelivery(now, "Calendar sync", "sent", "queued "+p.summary)
+	}
+	return saveOutbox(left)
+}
+
+// retryQueued flushes the outbox in the background.
+func (m model) retryQueued() tea.Cmd {
+	if !m.caldavPush || m.caldav.url == "" || m.dryRun {
+		return nil
+	}
+	c := m.caldav
+	return func() tea.Msg {
+		_ = flushOutbox(c, time.Now())
+		return nil
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFlushOutboxKeepsUID(t *testing.T) {
+	isolate(t)
+	var paths []string
+	status := http.StatusServiceUnavailable
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+	c := caldavClient{url: srv.URL + "/cal/"}
+
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	p := pendingPush{attempts: 1, next: now, start: now.Add(-time.Hour), end: now, uid: "abc", summary: "Focus"}
+	if err := queuePush(p); err != nil {
+		t.Fatal(err)
+	}
+	if err := flushOutbox(c, now); err != nil {
+		t.Fatal(err)
+	}
+	// The first attempt got through but its answer was lost; the server
+	// now refuses to create the event again.
+	status = http.StatusPreconditionFailed
+	if err := flushOutbox(c, now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 2 || paths[0] != "/cal/abc.ics" || paths[1] != paths[0] {
+		t.Errorf("PUT to %q, want /cal/abc.ics twice", paths)
+	}
+	if q := loadOutbox(); len(q) != 0 {
+		t.Errorf("%d pushes still queued, want none", len(q))
+	}
+}
+
+func TestParsePendingPushWithoutUID(t *testing.T) {
+	p, err := parsePendingPush("1\t2026-03-02T09:00:00Z\t2026-03-02T08:00:00Z\t2026-03-02T08:25:00Z\tFocus block")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.uid == "" || p.summary != "Focus block" {
+		t.Errorf("uid %q, summary %q; want a new uid and Focus block", p.uid, p.summary)
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// action is a command palette entry. Features register their actions
+// here so everything the keymap can do stays discoverable in one list.
+type action struct {
+	name      string
+	available func(m model) bool
+	run       func(m model) (tea.Model, tea.Cmd)
+}
+
+func always(model) bool { return true }
+
+func onInputScreen(m model) bool { return m.state == inputtingTime }
+
+func isCounting(m model) bool { return m.state != inputtingTime && !m.done }
+
+func isDone(m model) bool { return m.state != inputtingTime && m.done }
+
+func (m model) actions() []action {
+	acts := []action{
+		{
+			name:      "Search recent timers",
+			available: onInputScreen,
+			run:       model.openRecentFinder,
+		},
+		{
+			name:      "Pause timer",
+			available: func(m model) bool { return m.state == running },
+			run:       model.pauseOrResume,
+		},
+		{
+			name:      "Resume timer",
+			available: func(m model) bool { return m.state == paused },
+			run:       model.pauseOrResume,
+		},
+		{
+			name:      fmt.Sprintf("Add %s", formatDuration(m.adjustStep)),
+			available: isCounting,
+			run:       func(m model) (tea.Model, tea.Cmd) { return m.adjust(m.adjustStep) },
+		},
+		{
+			name:      fmt.Sprintf("Take off %s", formatDuration(m.adjustStep)),
+			available: isCounting,
+			run:       func(m model) (tea.Model, tea.Cmd) { return m.adjust(-m.adjustStep) },
+		},
+		{
+			name:      "Skip to next pomodoro phase",
+			available: func(m model) bool { return m.pomodoro != nil },
+			run: func(m model) (tea.Model, tea.Cmd) {
+				m, logged := m.recordHistory(statusCancelled)
+				return m.advancePomodoro(), logged
+			},
+		},
+		{
+			name:      "Restart timer",
+			available: isDone,
+			run:       func(m model) (tea.Model, tea.Cmd) { return m.summaryKey("r") },
+		},
+		{
+			name:      "Start break",
+			available: isDone,
+			run:       func(m model) (tea.Model, tea.Cmd) { return m.summaryKey("b") },
+		},
+		{
+			name:      "New timer",
+			available: isDone,
+			run:       func(m model) (tea.Model, tea.Cmd) { return m.summaryKey("n") },
+		},
+		{
+			name:      "Write a note",
+			available: isDone,
+			run:       func(m model) (tea.Model, tea.Cmd) { return m.summaryKey("w") },
+		},
+		{
+			name:      "Toggle big digits",
+			available: func(m model) bool { return m.state != inputtingTime },
+			run:       model.toggleBigDigits,
+		},
+		{
+			name:      "Cycle readout (remaining, elapsed, percent)",
+			available: func(m model) bool { return m.state != inputtingTime },
+			run:       model.toggleReadout,
+		},
+		{
+			name:      "Next color theme",
+			available: always,
+			run:       model.nextScheme,
+		},
+		{
+			name:      "Toggle high contrast",
+			available: always,
+			run:       model.toggleHighContrast,
+		},
+		{
+			name:      "Toggle reduced motion",
+			available: always,
+			run:       model.toggleReduceMotion,
+		},
+		{
+			name:      "Quit",
+			available: always,
+			run:       model.requestQuit,
+		},
+	}
+
+	for i, p := range m.quickPicks {
+		if p == "" {
+			continue
+		}
+		acts = append(acts, action{
+			name:      fmt.Sprintf("Start quick pick %d (%s)", i, p),
+			available: onInputScreen,
+			run:       func(m model) (tea.Model, tea.Cmd) { return m.start(p) },
+		})
+	}
+	for _, p := range m.presets {
+		acts = append(acts, action{
+			name:      fmt.Sprintf("Start preset %s (%s)", p.name, p.input),
+			available: onInputScreen,
+			run:       func(m model) (tea.Model, tea.Cmd) { return m.start(p.name) },
+		})
+	}
+	return acts
+}
+
+func (m model) openPalette() (tea.Model, tea.Cmd) {
+	available := map[string]action{}
+	var names []string
+	for _, a := range m.actions() {
+		if a.available(m) {
+			available[a.name] = a
+			names = append(names, a.name)
+		}
+	}
+
+	return m.showFinder(newFinder("> ", "Enter to run, Esc to close", names, func(m model, name string) (tea.Model, tea.Cmd) {
+		return available[name].run(m)
+	}))
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+const appName = "progress-timer"
+
+// Directory layout per platform:
+//
+//	         Linux/BSD (XDG)              macOS                                    Windows
+//	config   $XDG_CONFIG_HOME/..          ~/Library/Application Support/..         %APPDATA%\..
+//	data     $XDG_DATA_HOME/..            ~/Library/Application Support/..         %LOCALAPPDATA%\..
+//	state    $XDG_STATE_HOME/..           ~/Library/Application Support/../state   %LOCALAPPDATA%\..\state
+//
+// XDG variables are honoured on every platform when set, which keeps
+// dotfile setups portable.
+
+func homeDir() string {
+	if h, err := os.UserHomeDir(); err == nil {
+		return h
+	}
+	return "."
+}
+
+func xdgDir(env string, fallback ...string) string {
+	if d := os.Getenv(env); d != "" && filepath.IsAbs(d) {
+		return filepath.Join(d, appName)
+	}
+	return filepath.Join(append([]string{homeDir()}, fallback...)...)
+}
+
+func configDir() string {
+	if os.Getenv("XDG_CONFIG_HOME") == "" {
+		switch runtime.GOOS {
+		case "darwin":
+			return filepath.Join(homeDir(), "Library", "Application Support", appName)
+		case "windows":
+			if d := os.Getenv("APPDATA"); d != "" {
+				return filepath.Join(d, appName)
+			}
+		}
+	}
+	return xdgDir("XDG_CONFIG_HOME", ".config", appName)
+}
+
+func dataDir() string {
+	if os.Getenv("XDG_DATA_HOME") == "" {
+		switch runtime.GOOS {
+		case "darwin":
+			return filepath.Join(homeDir(), "Library", "Application Support", appName)
+		case "windows":
+			if d := os.Getenv("LOCALAPPDATA"); d != "" {
+				return filepath.Join(d, appName)
+			}
+		}
+	}
+	return xdgDir("XDG_DATA_HOME", ".local", "share", appName)
+}
+
+func stateDir() string {
+	if os.Getenv("XDG_STATE_HOME") == "" {
+		switch runtime.GOOS {
+		case "darwin":
+			return filepath.Join(homeDir(), "Library", "Application Support", appName, "state")
+		case "windows":
+			if d := os.Getenv("LOCALAPPDATA"); d != "" {
+				return filepath.Join(d, appName, "state")
+			}
+		}
+	}
+	return xdgDir("XDG_STATE_HOME", ".local", "state", appName)
+}
+
+func configFile() string {
+	if p := os.Getenv(envPrefix + "CONFIG"); p != "" {
+		return p
+	}
+	return filepath.Join(configDir(), "config.ini")
+}
+
+// projectFile is the settings overlay for --project name.
+func projectFile(name string) string {
+	return filepath.Join(configDir(), "projects", name+".ini")
+}
+
+func soundsDir() string {
+	return filepath.Join(dataDir(), "sounds")
+}
+
+// resolveSound looks up bare or relative sound names in soundsDir.
+func resolveSound(name string) string {
+	if name == "" || filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(soundsDir(), name)
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// A plan is one day of one-off timers, imported from a CSV file
+// ("09:00,50m,Focus") or a Markdown list or table ("- 09:00 50m Focus",
+// "| 09:00 | 50m | Focus |"). Header rows and other lines that do not
+// start with a time are skipped.
+
+func planFile() string {
+	return filepath.Join(stateDir(), "plan")
+}
+
+// planCells splits a line into time, duration and label, or returns nil
+// when it is not a planned block.
+func planCells(line string) []string {
+	line = strings.TrimSpace(line)
+	var cells []string
+	switch {
+	case strings.HasPrefix(line, "|"):
+		cells = strings.Split(strings.Trim(line, "|"), "|")
+	case strings.Contains(line, ","):
+		r := csv.NewReader(strings.NewReader(line))
+		r.TrimLeadingSpace = true
+		var err error
+		if cells, err = r.Read(); err != nil {
+			return nil
+		}
+	default:
+		for _, p := range []string{"- [ ] ", "- [x] ", "- ", "* "} {
+			line = strings.TrimPrefix(line, p)
+		}
+		cells = strings.SplitN(line, " ", 3)
+	}
+	for i := range cells {
+		cells[i] = strings.TrimSpace(cells[i])
+	}
+	if len(cells) < 2 {
+		return nil
+	}
+	if _, err := time.Parse("15:04", cells[0]); err != nil {
+		return nil
+	}
+	return cells
+}
+
+func parsePlan(r io.Reader, day time.Time) ([]schedule, error) {
+	var plan []schedule
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		cells := planCells(sc.Text())
+		if cells == nil {
+			continue
+		}
+		s, err := parseSchedule("daily " + strings.Join(cells, " "))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		s.date = day.Format(time.DateOnly)
+		plan = append(plan, s)
+	}
+	sort.SliceStable(plan, func(i, j int) bool {
+		return plan[i].hour*60+plan[i].minute < plan[j].hour*60+plan[j].minute
+	})
+	return plan, sc.Err()
+}
+
+func formatPlanBlock(s schedule) string {
+	return strings.TrimSpace(fmt.Sprintf("%02d:%02d %s %s", s.hour, s.minute, s.duration, s.label))
+}
+
+// loadPlan returns the blocks planned for day, if the saved plan is for
+// that day.
+func loadPlan(day time.Time) []schedule {
+	f, err := os.Open(planFile())
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var plan []schedule
+	sc := bufio.NewScanner(f)
+	if !sc.Scan() || sc.Text() != day.Format(time.DateOnly) {
+		return nil
+	}
+	for sc.Scan() {
+		if s, err := parseSchedule("daily " + sc.Text()); err == nil {
+			s.date = day.Format(time.DateOnly)
+			plan = append(plan, s)
+		}
+	}
+	return plan
+}
+
+func savePlan(plan []schedule, day time.Time) error {
+	if err := os.MkdirAll(stateDir(), 0o755); err != nil {
+		return err
+	}
+	var b strings.Builder
+	b.WriteString(day.Format(time.DateOnly) + "\n")
+	for _, s := range plan {
+		b.WriteString(formatPlanBlock(s) + "\n")
+	}
+	return os.WriteFile(planFile(), []byte(b.String()), 0o644)
+}
+
+func runPlanCommand(args []string) int {
+	now := time.Now()
+	switch {
+	case len(args) == 0:
+		plan := loadPlan(now)
+		if len(plan) == 0 {
+			fmt.Println("Nothing planned today. Load a plan with `progress-timer plan import FILE`.")
+			return 0
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, s := range plan {
+			fmt.Fprintf(w, "%02d:%02d\t%s\t%s\n", s.hour, s.minute, formatDuration(s.duration), s.label)
+		}
+		w.Flush()
+		return 0
+
+	case len(args) == 2 && args[0] == "import":
+		f, err := os.Open(args[1])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		defer f.Close()
+		plan, err := parsePlan(f, now)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", args[1], err)
+			return exitInvalid
+		}
+		if len(plan) == 0 {
+			fmt.Fprintf(os.Stderr, "%s: no planned blocks found\n", args[1])
+			return exitInvalid
+		}
+		if err := savePlan(plan, now); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		fmt.Printf("Planned %d block(s) for today. Run them with `progress-timer schedule run`.\n", len(plan))
+		return 0
+
+	case len(args) == 1 && args[0] == "week":
+		cfg, err := loadConfig(configFile())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitInvalid
+		}
+		holidays, err := cfg.loadHolidays()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitInvalid
+		}
+		return runWeekView(cfg, holidays, now)
+
+	default:
+		return usage("plan")
+	}
+}
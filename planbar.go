@@ -0,0 +1,136 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// segment is one phase of a pomodoro cycle or interval plan.
+type segment struct {
+	name     string // as phaseName gives it
+	duration time.Duration
+	rest     bool
+}
+
+// planSegments lays out the whole plan the current phase belongs to, and
+// which of its segments is running: all the rounds of an interval plan,
+// the pomodoro cycle up to and including its long break, or the steps of
+// a template. A plain timer has none.
+func (m model) planSegments() ([]segment, int) {
+	var segs []segment
+	current := 0
+	switch {
+	case m.pomodoro != nil:
+		p := m.pomodoro
+		for i := range p.cycles {
+			segs = append(segs, segment{work.String(), p.work, false})
+			if i < p.cycles-1 {
+				segs = append(segs, segment{shortBreak.String(), p.short, true})
+			} else {
+				segs = append(segs, segment{longBreak.String(), p.long, true})
+			}
+		}
+		if p.phase == work {
+			current = 2 * (p.completed % p.cycles)
+		} else {
+			// A break follows the work phase just finished.
+			current = 2*((p.completed-1)%p.cycles) + 1
+		}
+	case m.intervals != nil:
+		iv := m.intervals
+		for r := 1; r <= iv.rounds; r++ {
+			if r == iv.round {
+				current = len(segs)
+				if iv.kind == intervalRest {
+					current++
+				}
+			}
+			segs = append(segs, segment{intervalWork.String(), iv.work, false})
+			if iv.rest > 0 && r < iv.rounds {
+				segs = append(segs, segment{intervalRest.String(), iv.rest, true})
+			}
+		}
+	case m.chain != nil:
+		// Template steps alternate colours so neighbours can be told
+		// apart.
+		for i, s := range m.chain.steps {
+			segs = append(segs, segment{s.label, s.duration, i%2 == 1})
+		}
+		current = m.chain.at
+	}
+	if current < len(segs) {
+		// An adjustment to the running phase shows in the plan.
+		segs[current].duration = m.countdown.Duration
+	}
+	return segs, current
+}
+
+// planBarView is the whole plan as one bar divided into its phases, work
+// and rest coloured apart, with a marker where the timer has got to. In
+// monochrome, rest is drawn with a shaded character instead.
+func (m model) planBarView() string {
+	segs, current := m.planSegments()
+	if len(segs) < 2 {
+		return ""
+	}
+	var total, at time.Duration
+	for i, s := range segs {
+		total += s.duration
+		if i < current {
+			at += s.duration
+		}
+	}
+	at += m.countdown.Duration - m.timeRemaining
+	if total <= 0 {
+		return ""
+	}
+
+	width := m.barWidth()
+	full, empty, shade, marker := string(m.theme.bar.full), string(m.theme.bar.empty), "▒", "┃"
+	if m.theme.asciiBar {
+		full, empty, shade, marker = "#", "-", "=", "|"
+	}
+	workColor := m.theme.barFrom
+	if m.intervals != nil {
+		workColor = m.theme.error
+	}
+	pos := min(int(float64(width)*float64(at)/float64(total)), width-1)
+
+	var b strings.Builder
+	var end time.Duration
+	cell := 0
+	for _, s := range segs {
+		end += s.duration
+		// Boundaries are rounded from the running total, so the cells add
+		// up to the width however the phases divide.
+		upto := int(float64(width)*float64(end)/float64(total) + 0.5)
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color(workColor))
+		fill := full
+		if s.rest {
+			style = lipgloss.NewStyle().Foreground(lipgloss.Color(m.theme.done))
+			if m.theme.monochrome() {
+				fill = shade
+			}
+		}
+		if m.theme.monochrome() {
+			style = lipgloss.NewStyle()
+		}
+		var run strings.Builder
+		for ; cell < upto; cell++ {
+			switch {
+			case cell == pos:
+				b.WriteString(style.Render(run.String()))
+				run.Reset()
+				b.WriteString(statusMessageStyle.Render(marker))
+			case cell < pos:
+				run.WriteString(fill)
+			default:
+				run.WriteString(empty)
+			}
+		}
+		b.WriteString(style.Render(run.String()))
+	}
+	return b.String()
+}
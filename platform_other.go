@@ -0,0 +1,53 @@
+//go:build !windows
+
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func altScreenSupported() bool {
+	return true
+}
+
+func pollWindowSize() tea.Cmd {
+	return nil
+}
+
+func enableVirtualTerminal() (restore func()) {
+	return func() {}
+}
+
+// shellCommand runs cmdline through the user's shell with args appended
+// as positional parameters, so they need no quoting.
+func shellCommand(cmdline string, args ...string) *exec.Cmd {
+	return exec.Command("sh", append([]string{"-c", cmdline + ` "$@"`, "sh"}, args...)...)
+}
+
+// pauseSignals toggle pause in headless mode.
+func pauseSignals() []os.Signal {
+	return []os.Signal{syscall.SIGUSR1}
+}
+
+// findPID reports whether pid names a running process.
+func findPID(pid int) error {
+	if err := syscall.Kill(pid, 0); errors.Is(err, syscall.ESRCH) {
+		return errors.New("no such process")
+	}
+	return nil
+}
+
+// waitPID polls until pid is gone. Only a parent may collect a process's
+// exit status, so there is none.
+func waitPID(pid int) exitedMsg {
+	for findPID(pid) == nil {
+		time.Sleep(time.Second)
+	}
+	return exitedMsg{noStatus: true}
+}
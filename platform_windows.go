@@ -0,0 +1,113 @@
+//go:build windows
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/term"
+	"github.com/muesli/termenv"
+)
+
+// What else Windows needs, or doesn't:
+//
+//   - Ctrl+C: Bubble Tea puts the console in raw mode, so Ctrl+C arrives
+//     as a key like anywhere else. Headless, Go delivers Ctrl+C and
+//     Ctrl+Break as os.Interrupt, and closing the console window as
+//     SIGTERM, both of which runHeadless already handles.
+//   - Colors: Bubble Tea turns on VT processing for its programs, and
+//     termenv picks the profile from the Windows build, so the TUI and
+//     stats degrade like elsewhere. Only output written outside Bubble
+//     Tea needs enableVirtualTerminal.
+//   - Sound: the bell is the console's; sound files go to SoundPlayer,
+//     which plays only wav, so doctor flags any other kind.
+//   - Paths: config, data and state live under APPDATA and LOCALAPPDATA
+//     (see paths.go).
+
+// modernTerminal reports whether we are running inside a terminal that
+// implements the full VT feature set. The legacy conhost window renders
+// the alternate screen without clearing scrollback and leaves artifacts on
+// resize, so it is treated as a plain console.
+func modernTerminal() bool {
+	switch {
+	case os.Getenv("WT_SESSION") != "":
+		return true
+	case os.Getenv("ConEmuANSI") == "ON":
+		return true
+	case os.Getenv("TERM_PROGRAM") != "":
+		return true
+	}
+	return false
+}
+
+func altScreenSupported() bool {
+	return modernTerminal()
+}
+
+// Windows has no SIGWINCH, so Bubble Tea only reports the initial size.
+// Polling on every tick keeps the layout in step with resizes.
+func pollWindowSize() tea.Cmd {
+	return func() tea.Msg {
+		w, h, err := term.GetSize(os.Stdout.Fd())
+		if err != nil {
+			return nil
+		}
+		return tea.WindowSizeMsg{Width: w, Height: h}
+	}
+}
+
+// enableVirtualTerminal makes the legacy console interpret escape
+// sequences written outside Bubble Tea, such as doctor's cursor queries.
+// The returned function restores the console.
+func enableVirtualTerminal() (restore func()) {
+	r, err := termenv.EnableVirtualTerminalProcessing(termenv.NewOutput(os.Stdout))
+	if err != nil || r == nil {
+		return func() {}
+	}
+	return func() { _ = r() }
+}
+
+// shellCommand runs cmdline through cmd.exe with args appended, each in
+// double quotes, which Windows paths can't contain. The command line is
+// handed over as written: Go's own quoting escapes embedded quotes with
+// backslashes, which cmd.exe doesn't understand. /S makes cmd.exe strip
+// just the outer pair of quotes and keep the rest.
+func shellCommand(cmdline string, args ...string) *exec.Cmd {
+	line := cmdline
+	for _, a := range args {
+		line += ` "` + a + `"`
+	}
+	c := exec.Command("cmd")
+	c.SysProcAttr = &syscall.SysProcAttr{CmdLine: `cmd /S /C "` + line + `"`}
+	return c
+}
+
+// Windows has no user signals, so a headless timer can't be paused.
+func pauseSignals() []os.Signal {
+	return nil
+}
+
+func findPID(pid int) error {
+	p, err := os.FindProcess(pid)
+	if err == nil {
+		p.Release()
+	}
+	return err
+}
+
+// waitPID waits for pid to exit. Windows hands out the exit code to
+// anyone holding a handle to the process.
+func waitPID(pid int) exitedMsg {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return exitedMsg{noStatus: true}
+	}
+	st, err := p.Wait()
+	if err != nil {
+		return exitedMsg{noStatus: true}
+	}
+	return exitedMsg{code: st.ExitCode()}
+}
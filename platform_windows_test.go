@@ -0,0 +1,13 @@
+//go:build windows
+
+package main
+
+import "testing"
+
+func TestShellCommandQuotes(t *testing.T) {
+	c := shellCommand(`"C:\Program Files\tool.exe" --say "done"`, `C:\sounds\bell.wav`)
+	want := `cmd /S /C ""C:\Program Files\tool.exe" --say "done" "C:\sounds\bell.wav""`
+	if c.SysProcAttr == nil || c.SysProcAttr.CmdLine != want {
+		t.Errorf("command line %q, want %q", c.SysProcAttr.CmdLine, want)
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type phase int
+
+const (
+	work phase = iota
+	shortBreak
+	longBreak
+)
+
+func (p phase) String() string {
+	switch p {
+	case shortBreak:
+		return "Short break"
+	case longBreak:
+		return "Long break"
+	}
+	return "Work"
+}
+
+// pomodoro cycles work and breaks: a long break after every cycles work
+// phases, short ones in between.
+type pomodoro struct {
+	work, short, long time.Duration
+	cycles            int
+	phase             phase
+	completed         int // work phases finished
+}
+
+// parsePomodoro reads work/short/long[xcycles], e.g. 25/5/15x4 or
+// 50m/10m/30m. Bare numbers are minutes; cycles default to 4.
+func parsePomodoro(s string) (pomodoro, error) {
+	p := pomodoro{cycles: 4}
+	spec, cycles, ok := strings.Cut(s, "x")
+	if ok {
+		n, err := strconv.Atoi(cycles)
+		if err != nil || n < 1 {
+			return p, fmt.Errorf("%q: cycles must be a positive number", s)
+		}
+		p.cycles = n
+	}
+	parts := strings.Split(spec, "/")
+	if len(parts) != 3 {
+		return p, fmt.Errorf("%q should look like 25/5/15x4 (work/short break/long break x cycles)", s)
+	}
+	for i, dst := range []*time.Duration{&p.work, &p.short, &p.long} {
+		d, err := parseDuration(parts[i])
+		if err != nil {
+			return p, fmt.Errorf("%q: %w", parts[i], err)
+		}
+		*dst = d
+	}
+	return p, nil
+}
+
+func (p pomodoro) duration() time.Duration {
+	switch p.phase {
+	case shortBreak:
+		return p.short
+	case longBreak:
+		return p.long
+	}
+	return p.work
+}
+
+// next moves on from the current phase.
+func (p pomodoro) next() pomodoro {
+	if p.phase != work {
+		p.phase = work
+		return p
+	}
+	p.completed++
+	if p.completed%p.cycles == 0 {
+		p.phase = longBreak
+	} else {
+		p.phase = shortBreak
+	}
+	return p
+}
+
+// advancePomodoro finishes the current phase and starts the next one.
+// Only work phases count towards a named session.
+func (m model) advancePomodoro() model {
+	if m.pomodoro.phase == work {
+		m.sessionTotal += m.sittingTime()
+	}
+	p := m.pomodoro.next()
+	m.pomodoro = &p
+	m = m.begin(p.duration())
+	m.phaseChanged = true
+	return m
+}
+
+// pomodoroView is the phase line above the timer, e.g.
+// "Work · 3 of 4 · 2 done".
+func (m model) pomodoroView() string {
+	p := m.pomodoro
+	cycle := p.completed%p.cycles + 1
+	if p.phase != work {
+		// A break belongs to the work phase just finished.
+		cycle = (p.completed-1)%p.cycles + 1
+	}
+	return fmt.Sprintf("%s · %d of %d · %d done", p.phase, cycle, p.cycles, p.completed)
+}
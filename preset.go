@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// preset is a named duration from the config, e.g. tea=3m.
+type preset struct {
+	name  string
+	input string
+}
+
+type presets []preset
+
+// parsePresets reads "tea=3m, standup=15m". The duration is anything the
+// input screen accepts.
+func parsePresets(v string) (presets, error) {
+	var ps presets
+	for _, f := range strings.Split(v, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		name, input, ok := strings.Cut(f, "=")
+		name, input = strings.TrimSpace(name), strings.TrimSpace(input)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("%q should look like name=duration", f)
+		}
+		if _, err := parseDuration(input); err != nil {
+			return nil, fmt.Errorf("%s: %q is not a duration", name, input)
+		}
+		ps = append(ps, preset{name, input})
+	}
+	return ps, nil
+}
+
+// lookup returns the duration of the preset called name, ignoring case.
+func (ps presets) lookup(name string) (string, bool) {
+	for _, p := range ps {
+		if strings.EqualFold(p.name, strings.TrimSpace(name)) {
+			return p.input, true
+		}
+	}
+	return "", false
+}
+
+func (ps presets) names() []string {
+	names := make([]string, len(ps))
+	for i, p := range ps {
+		names[i] = p.name
+	}
+	return names
+}
+
+func (m model) openPresetFinder() (tea.Model, tea.Cmd) {
+	return m.showFinder(newFinder("Preset: ", "Enter to start, Esc to close", m.presets.names(), model.start))
+}
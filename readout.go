@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// readoutModes are what the main readout can show, in the order the e key
+// cycles through them.
+var readoutModes = []string{"remaining", "elapsed", "percent"}
+
+// readoutFile remembers the readout last chosen with e for each profile,
+// one "profile<TAB>mode" line each. The profile is the --project name, or
+// "default".
+func readoutFile() string {
+	return filepath.Join(stateDir(), "readout.tsv")
+}
+
+func profileKey(project string) string {
+	if project == "" {
+		return "default"
+	}
+	return project
+}
+
+func loadReadouts() map[string]string {
+	out := map[string]string{}
+	f, err := os.Open(readoutFile())
+	if err != nil {
+		return out
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if profile, mode, ok := strings.Cut(sc.Text(), "\t"); ok {
+			out[profile] = mode
+		}
+	}
+	return out
+}
+
+// loadReadout is the remembered readout for project, if there is one.
+func loadReadout(project string) (string, bool) {
+	mode, ok := loadReadouts()[profileKey(project)]
+	return mode, ok
+}
+
+func saveReadout(project, mode string) tea.Cmd {
+	return func() tea.Msg {
+		all := loadReadouts()
+		all[profileKey(project)] = mode
+		var b strings.Builder
+		for profile, mode := range all {
+			fmt.Fprintf(&b, "%s\t%s\n", profile, mode)
+		}
+		if err := os.MkdirAll(stateDir(), 0o755); err != nil {
+			return nil
+		}
+		_ = os.WriteFile(readoutFile(), []byte(b.String()), 0o644)
+		return nil
+	}
+}
+
+// readoutLabel names what the readout shows.
+func (m model) readoutLabel() string {
+	switch m.readoutMode {
+	case "elapsed":
+		return "Time elapsed:"
+	case "percent":
+		return "Progress:"
+	}
+	return "Time remaining:"
+}
+
+// toggleReadout moves the readout on to the next mode and remembers it
+// for the profile.
+func (m model) toggleReadout() (tea.Model, tea.Cmd) {
+	i := 0
+	for j, mode := range readoutModes {
+		if mode == m.readoutMode {
+			i = j
+		}
+	}
+	m.readoutMode = readoutModes[(i+1)%len(readoutModes)]
+	return m, saveReadout(m.project, m.readoutMode)
+}
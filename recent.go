@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const maxRecent = 10
+
+func recentFile() string {
+	return filepath.Join(stateDir(), "recent")
+}
+
+// loadRecent returns previously started inputs, most recent first.
+func loadRecent() []string {
+	f, err := os.Open(recentFile())
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var recent []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() && len(recent) < maxRecent {
+		if line := strings.TrimSpace(sc.Text()); line != "" {
+			recent = append(recent, line)
+		}
+	}
+	return recent
+}
+
+// pushRecent moves input to the front of recent, dropping duplicates.
+func pushRecent(recent []string, input string) []string {
+	out := []string{input}
+	for _, r := range recent {
+		if r != input && len(out) < maxRecent {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func saveRecent(recent []string) tea.Cmd {
+	return func() tea.Msg {
+		if err := os.MkdirAll(stateDir(), 0o755); err != nil {
+			return nil
+		}
+		_ = os.WriteFile(recentFile(), []byte(strings.Join(recent, "\n")+"\n"), 0o644)
+		return nil
+	}
+}
+
+// suggestions lists the presets and recent inputs starting with what has
+// been typed so far.
+func (m model) suggestions() []string {
+	typed := strings.TrimSpace(m.textInput.Value())
+	var out []string
+	if typed != "" {
+		for _, name := range m.presets.names() {
+			if strings.HasPrefix(strings.ToLower(name), strings.ToLower(typed)) && !strings.EqualFold(name, typed) {
+				out = append(out, name)
+			}
+		}
+	}
+	for _, r := range m.recent {
+		if strings.HasPrefix(r, typed) && r != typed {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func (m model) suggestionsView() string {
+	var b strings.Builder
+	for i, s := range m.suggestions() {
+		if i == m.suggestion {
+			b.WriteString(statusMessageStyle.Render("> " + s))
+		} else {
+			b.WriteString("  " + s)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
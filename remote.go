@@ -0,0 +1,24 @@
+package main
+
+import "os"
+
+// remoteFPS caps redraws over SSH. The timer changes once a second, so
+// Bubble Tea's default 60 fps only costs bandwidth there.
+const remoteFPS = 4
+
+// sshSession reports whether we are running over SSH.
+func sshSession() bool {
+	return os.Getenv("SSH_CONNECTION") != "" || os.Getenv("SSH_CLIENT") != "" || os.Getenv("SSH_TTY") != ""
+}
+
+// isRemote resolves the remote setting. Remote mode redraws less often,
+// stops the cursor blinking and sticks to ASCII glyphs.
+func (c config) isRemote() bool {
+	switch c.remote {
+	case "on":
+		return true
+	case "off":
+		return false
+	}
+	return sshSession()
+}
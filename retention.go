@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Retention keeps the history from growing without end. Timers older than
+// history.keep are folded into daily totals, one tab-separated line per
+// day, starting hour, status and label:
+//
+//	day  hour  status  count  planned  actual  overran  overrun  label
+//
+// and dropped from the journal. Stats read the totals alongside the
+// history, so the charts and streaks survive pruning; the history listing
+// and estimates only see what is kept raw. Totals older than
+// history.keep_daily are dropped in turn.
+
+type retention struct {
+	raw   time.Duration // 0 keeps every timer
+	daily time.Duration // 0 keeps the totals forever
+}
+
+// parseRetention reads a period such as 90d, 12w or 1y, or a Go
+// duration. Empty and "forever" keep everything.
+func parseRetention(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "forever" {
+		return 0, nil
+	}
+	days := map[byte]int{'d': 1, 'w': 7, 'y': 365}
+	if n, err := strconv.Atoi(s[:len(s)-1]); err == nil && days[s[len(s)-1]] > 0 && n > 0 {
+		return time.Duration(n*days[s[len(s)-1]]) * 24 * time.Hour, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("%q is not a period like 90d, 12w or 1y, or forever", s)
+}
+
+type dailyTotal struct {
+	day     string // YYYY-MM-DD, local time
+	hour    int
+	status  string
+	count   int
+	planned time.Duration
+	actual  time.Duration
+	overran int
+	overrun time.Duration
+	label   string
+}
+
+func dailyFile() string {
+	return filepath.Join(dataDir(), "daily.tsv")
+}
+
+func (t dailyTotal) String() string {
+	return strings.Join([]string{
+		t.day, strconv.Itoa(t.hour), t.status, strconv.Itoa(t.count), t.planned.String(),
+		t.actual.String(), strconv.Itoa(t.overran), t.overrun.String(), strings.Join(strings.Fields(t.label), " "),
+	}, "\t")
+}
+
+func parseDailyTotal(line string) (dailyTotal, error) {
+	f := strings.SplitN(line, "\t", 9)
+	if len(f) != 9 {
+		return dailyTotal{}, fmt.Errorf("expected 9 fields, got %d", len(f))
+	}
+	t := dailyTotal{day: f[0], status: f[2], label: f[8]}
+	if _, err := time.Parse(time.DateOnly, t.day); err != nil {
+		return t, err
+	}
+	var errs [6]error
+	t.hour, errs[0] = strconv.Atoi(f[1])
+	t.count, errs[1] = strconv.Atoi(f[3])
+	t.planned, errs[2] = time.ParseDuration(f[4])
+	t.actual, errs[3] = time.ParseDuration(f[5])
+	t.overran, errs[4] = strconv.Atoi(f[6])
+	t.overrun, errs[5] = time.ParseDuration(f[7])
+	return t, errors.Join(errs[:]...)
+}
+
+func loadDaily() []dailyTotal {
+	f, err := os.Open(dailyFile())
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var out []dailyTotal
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if t, err := parseDailyTotal(sc.Text()); err == nil {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// writeLines replaces path with lines, through a rename so a crash can't
+// leave it half written.
+func writeLines(path string, lines []string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	var b strings.Builder
+	for _, l := range lines {
+		b.WriteString(l + "\n")
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(b.String()), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// foldDaily adds entries to the totals, dating them in loc.
+func foldDaily(totals []dailyTotal, entries []historyEntry, loc *time.Location) []dailyTotal {
+	type key struct {
+		day    string
+		hour   int
+		status string
+		label  string
+	}
+	at := map[key]int{}
+	for i, t := range totals {
+		at[key{t.day, t.hour, t.status, t.label}] = i
+	}
+	for _, e := range entries {
+		start := e.start.In(loc)
+		label := strings.Join(strings.Fields(e.label), " ")
+		k := key{start.Format(time.DateOnly), start.Hour(), e.status, label}
+		i, ok := at[k]
+		if !ok {
+			i = len(totals)
+			at[k] = i
+			totals = append(totals, dailyTotal{day: k.day, hour: k.hour, status: k.status, label: k.label})
+		}
+		t := &totals[i]
+		t.count++
+		t.planned += e.planned
+		t.actual += e.actual
+		if e.status != statusCancelled && e.actual > e.planned {
+			t.overran++
+			t.overrun += e.actual - e.planned
+		}
+	}
+	sort.SliceStable(totals, func(i, j int) bool {
+		if totals[i].day != totals[j].day {
+			return totals[i].day < totals[j].day
+		}
+		return totals[i].hour < totals[j].hour
+	})
+	return totals
+}
+
+// pruneHistory folds the timers that started before the retention period
+// into the daily totals and drops them from the journal and history.tsv,
+// then drops the totals that are too old. It returns how many timers it
+// folded. Files are only rewritten when there is something to prune.
+func pruneHistory(r retention, now time.Time) (int, error) {
+	var old []historyEntry
+	if r.raw > 0 {
+		cutoff := now.Add(-r.raw)
+		events := loadEvents()
+		sort.SliceStable(events, func(i, j int) bool { return events[i].at.Before(events[j].at) })
+		stale := map[string]bool{}
+		for _, e := range events {
+			if e.kind == "start" && e.at.Before(cutoff) {
+				stale[e.timer] = true
+			}
+			// The second part of a split goes with the first.
+			if e.kind == "split" && stale[e.timer] {
+				stale[e.value] = true
+			}
+		}
+		var keep, drop []event
+		for _, e := range events {
+			if stale[e.timer] {
+				drop = append(drop, e)
+			} else {
+				keep = append(keep, e)
+			}
+		}
+		old = replayEvents(drop)
+
+		var legacy []string
+		var legacyOld []historyEntry
+		if f, err := os.Open(historyFile()); err == nil {
+			sc := bufio.NewScanner(f)
+			for sc.Scan() {
+				if e, err := parseHistoryEntry(sc.Text()); err == nil && e.start.Before(cutoff) {
+					legacyOld = append(legacyOld, e)
+					continue
+				}
+				legacy = append(legacy, sc.Text())
+			}
+			f.Close()
+		}
+		old = append(old, legacyOld...)
+
+		// The totals go first: a crash before the journal is rewritten
+		// counts those timers twice in stats rather than not at all.
+		if len(old) > 0 {
+			if err := writeDaily(foldDaily(loadDaily(), old, now.Location())); err != nil {
+				return 0, err
+			}
+		}
+		if len(drop) > 0 {
+			lines := make([]string, len(keep))
+			for i, e := range keep {
+				lines[i] = e.String()
+			}
+			if err := writeLines(eventsFile(), lines); err != nil {
+				return 0, err
+			}
+		}
+		if len(legacyOld) > 0 {
+			if err := writeLines(historyFile(), legacy); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	if r.daily > 0 {
+		cutoff := now.Add(-r.daily).Format(time.DateOnly)
+		totals := loadDaily()
+		kept := totals[:0:0]
+		for _, t := range totals {
+			if t.day >= cutoff {
+				kept = append(kept, t)
+			}
+		}
+		if len(kept) < len(totals) {
+			if err := writeDaily(kept); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return len(old), nil
+}
+
+func writeDaily(totals []dailyTotal) error {
+	lines := make([]string, len(totals))
+	for i, t := range totals {
+		lines[i] = t.String()
+	}
+	return writeLines(dailyFile(), lines)
+}
+
+func runPruneCommand(args []string) int {
+	if len(args) > 1 {
+		return usage("prune")
+	}
+	cfg, err := loadConfig(configFile())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitInvalid
+	}
+	r := cfg.retention
+	if len(args) == 1 {
+		if r.raw, err = parseRetention(args[0]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitInvalid
+		}
+	}
+	if r.raw == 0 && r.daily == 0 {
+		fmt.Println("Nothing to prune: set history.keep, or give a period such as 1y.")
+		return 0
+	}
+	n, err := pruneHistory(r, time.Now())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	fmt.Printf("Folded %d timers into daily totals.\n", n)
+	return 0
+}
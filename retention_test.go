@@ -0,0 +1,80 @@
+package main
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestParseRetention(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"", 0, true},
+		{"forever", 0, true},
+		{"90d", 90 * 24 * time.Hour, true},
+		{"2w", 14 * 24 * time.Hour, true},
+		{"1y", 365 * 24 * time.Hour, true},
+		{"720h", 720 * time.Hour, true},
+		{"0d", 0, false},
+		{"y", 0, false},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := parseRetention(tt.in)
+		if got != tt.want || (err == nil) != tt.ok {
+			t.Errorf("parseRetention(%q) = %s, %v; want %s, ok %v", tt.in, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestPruneHistory(t *testing.T) {
+	isolate(t)
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	old := now.AddDate(-2, 0, 0)
+	timer := func(start time.Time, planned, actual time.Duration, label string) []event {
+		id := start.Format(time.RFC3339Nano)
+		return []event{
+			{at: start, timer: id, kind: "start", value: planned.String(), label: label},
+			{at: start.Add(actual), timer: id, kind: "end", value: statusCompleted},
+		}
+	}
+	var events []event
+	events = append(events, timer(old, 25*time.Minute, 25*time.Minute, "Focus")...)
+	events = append(events, timer(old.Add(time.Hour), 25*time.Minute, 30*time.Minute, "Focus")...)
+	events = append(events, timer(now.Add(-time.Hour), 50*time.Minute, 50*time.Minute, "Write")...)
+	if err := appendEvents(events...); err != nil {
+		t.Fatal(err)
+	}
+	legacy := historyEntry{start: old.Add(-24 * time.Hour), end: old, status: statusCancelled,
+		planned: time.Hour, actual: 10 * time.Minute, label: "Focus"}
+	if err := os.WriteFile(historyFile(), []byte(legacy.String()+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	before := computeStats(loadHistory(), loadDaily(), now)
+
+	n, err := pruneHistory(retention{raw: 365 * 24 * time.Hour}, now)
+	if err != nil || n != 3 {
+		t.Fatalf("pruned %d, %v; want 3 timers", n, err)
+	}
+	if h := loadHistory(); len(h) != 1 || h[0].label != "Write" {
+		t.Errorf("history after pruning %+v, want the Write timer alone", h)
+	}
+	if d := loadDaily(); len(d) != 3 || d[1].count != 1 || d[1].overran != 0 || d[2].overrun != 5*time.Minute {
+		t.Errorf("daily totals %+v, want 3 rows, the last overrun by 5m0s", d)
+	}
+	after := computeStats(loadHistory(), loadDaily(), now)
+	if after.total != before.total || after.completed != before.completed || after.cancelled != before.cancelled ||
+		after.overrun != before.overrun || after.perLabel["Focus"] != before.perLabel["Focus"] {
+		t.Errorf("stats changed by pruning: %+v, was %+v", after, before)
+	}
+
+	if _, err := pruneHistory(retention{daily: 365 * 24 * time.Hour}, now); err != nil {
+		t.Fatal(err)
+	}
+	if d := loadDaily(); len(d) != 0 {
+		t.Errorf("daily totals past history.keep_daily kept: %+v", d)
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// The end-of-day review sums up the day's timers from the history and asks
+// for a line of reflection, which goes to the journal next to the notes.
+// It opens at review.time, or on the first launch after it, once a day:
+// the day it was last shown is kept in the state directory, skipped or
+// not.
+
+func reviewedFile() string {
+	return filepath.Join(stateDir(), "reviewed")
+}
+
+// reviewedOn is the day the review was last shown, YYYY-MM-DD.
+func reviewedOn() string {
+	b, err := os.ReadFile(reviewedFile())
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+func setReviewed(day time.Time) error {
+	if err := os.MkdirAll(stateDir(), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(reviewedFile(), []byte(day.Format(time.DateOnly)+"\n"), 0o644)
+}
+
+// reviewDue reports whether the review should open at now: review.time has
+// passed today and the review wasn't shown today already.
+func reviewDue(at string, now time.Time, reviewed string) bool {
+	t, err := time.Parse("15:04", at)
+	if err != nil {
+		return false
+	}
+	y, mo, d := now.Date()
+	due := time.Date(y, mo, d, t.Hour(), t.Minute(), 0, 0, now.Location())
+	return !now.Before(due) && reviewed != now.Format(time.DateOnly)
+}
+
+// review is the review screen.
+type review struct {
+	day        time.Time
+	entries    []historyEntry // the day's, oldest first
+	reflection textinput.Model
+}
+
+func newReview(now time.Time, width int) review {
+	var today []historyEntry
+	for _, e := range loadHistory() {
+		if e.start.In(now.Location()).Format(time.DateOnly) == now.Format(time.DateOnly) {
+			today = append(today, e)
+		}
+	}
+	ti := textinput.New()
+	ti.Prompt = "Reflection: "
+	ti.Placeholder = "how did today go?"
+	ti.CharLimit = 200
+	if width > 0 {
+		ti.Width = max(width-len(ti.Prompt)-5, 10)
+	}
+	ti.Focus()
+	return review{day: now, entries: today, reflection: ti}
+}
+
+// total is the time the day's timers ran and how many finished.
+func (r review) total() (time.Duration, int) {
+	var total time.Duration
+	finished := 0
+	for _, e := range r.entries {
+		total += e.actual
+		if e.status != statusCancelled {
+			finished++
+		}
+	}
+	return total, finished
+}
+
+// journalEntry is the reflection as a journal line, with the day's totals
+// so the journal reads on its own.
+func (r review) journalEntry(text string) string {
+	total, finished := r.total()
+	return fmt.Sprintf("%s review (%d of %d timers finished, %s): %s", r.day.Format("2006-01-02 15:04"),
+		finished, len(r.entries), formatDuration(total), text)
+}
+
+// update edits the reflection. Enter saves it, Esc skips it; either way
+// the review is done for the day, which the returned bool reports.
+func (r review) update(msg tea.KeyMsg) (review, tea.Cmd, bool) {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyEnter:
+		text := strings.TrimSpace(r.reflection.Value())
+		day := r.day
+		marked := func() tea.Msg {
+			_ = setReviewed(day)
+			return nil
+		}
+		if msg.Type == tea.KeyEsc || text == "" {
+			return r, marked, true
+		}
+		return r, tea.Batch(marked, appendJournal(r.journalEntry(text))), true
+	}
+	var cmd tea.Cmd
+	r.reflection, cmd = r.reflection.Update(msg)
+	return r, cmd, false
+}
+
+func (r review) view() string {
+	bold := lipgloss.NewStyle().Bold(true)
+	var b strings.Builder
+	b.WriteString(bold.Render("Today, "+r.day.Format("Monday January 2")) + "\n\n")
+	if len(r.entries) == 0 {
+		b.WriteString("No timers today.\n")
+	} else {
+		total, finished := r.total()
+		fmt.Fprintf(&b, "%d of %d timers finished, %s in all\n\n", finished, len(r.entries), formatDuration(total))
+		perLabel := map[string]time.Duration{}
+		for _, e := range r.entries {
+			label := e.label
+			if label == "" {
+				label = "(no label)"
+			}
+			perLabel[label] += e.actual
+		}
+		labels := make([]string, 0, len(perLabel))
+		for l := range perLabel {
+			labels = append(labels, l)
+		}
+		sort.Strings(labels)
+		sort.SliceStable(labels, func(i, j int) bool { return perLabel[labels[i]] > perLabel[labels[j]] })
+		for _, l := range labels {
+			fmt.Fprintf(&b, "%s %8s\n", placeText(l, 20), formatDuration(perLabel[l]))
+		}
+	}
+	b.WriteString("\n" + r.reflection.View() + "\n")
+	b.WriteString("Enter saves to the journal, Esc skips\n")
+	return b.String()
+}
+
+// reviewProgram runs the review on its own, for the review command.
+type reviewProgram struct {
+	review review
+}
+
+func (p reviewProgram) Init() tea.Cmd { return textinput.Blink }
+
+func (p reviewProgram) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		if msg.Type == tea.KeyCtrlC {
+			return p, tea.Quit
+		}
+		r, cmd, done := p.review.update(msg)
+		p.review = r
+		if done {
+			return p, tea.Sequence(cmd, tea.Quit)
+		}
+		return p, cmd
+	}
+	var cmd tea.Cmd
+	p.review.reflection, cmd = p.review.reflection.Update(msg)
+	return p, cmd
+}
+
+func (p reviewProgram) View() string {
+	return lipgloss.NewStyle().Margin(1, 2).Render(p.review.view())
+}
+
+func runReviewCommand(args []string) int {
+	if len(args) > 0 {
+		return usage("review")
+	}
+	cfg, err := loadConfig(configFile())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitInvalid
+	}
+	setStyles(cfg.theme())
+	p := reviewProgram{review: newReview(time.Now(), 0)}
+	if _, err := tea.NewProgram(p, cfg.programOptions()...).Run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	return 0
+}
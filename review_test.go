@@ -0,0 +1,70 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestReviewDue(t *testing.T) {
+	now := time.Date(2026, 3, 2, 17, 45, 0, 0, time.UTC)
+	tests := []struct {
+		at, reviewed string
+		want         bool
+	}{
+		{"17:30", "", true},
+		{"17:30", "2026-03-01", true},
+		{"17:30", "2026-03-02", false},
+		{"18:00", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		if got := reviewDue(tt.at, now, tt.reviewed); got != tt.want {
+			t.Errorf("reviewDue(%q, reviewed %q) = %v, want %v", tt.at, tt.reviewed, got, tt.want)
+		}
+	}
+}
+
+func TestReviewInTimerList(t *testing.T) {
+	m, clk := overtimeModel(t, true, time.Minute)
+	_, logged := m.recordFinished()
+	logged()
+
+	cfg := defaultConfig()
+	cfg.reviewTime = "09:30"
+	var l tea.Model = newTimerList(cfg, timerSetup{}, m)
+	if l.(timerList).review != nil {
+		t.Fatal("review open before review.time")
+	}
+	clk.advance(29 * time.Minute)
+	l, _ = l.Update(tickMsg(clk.now))
+	tl := l.(timerList)
+	if tl.review == nil {
+		t.Fatal("review not open at review.time")
+	}
+	if view := tl.View(); !strings.Contains(view, "1 of 1 timers finished, 01:00 in all") {
+		t.Errorf("review view lacks the day's total:\n%s", view)
+	}
+
+	l, _ = l.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("focused morning")})
+	l, cmd := l.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	runHeadlessCmd(cmd)
+	if tl = l.(timerList); tl.review != nil {
+		t.Error("review still open after Enter")
+	}
+	journal, _ := os.ReadFile(journalFile())
+	if want := "- 2026-03-02 09:30 review (1 of 1 timers finished, 01:00): focused morning\n"; string(journal) != want {
+		t.Errorf("journal %q, want %q", journal, want)
+	}
+
+	l, _ = l.Update(tickMsg(clk.advance(time.Minute)))
+	if l.(timerList).review != nil {
+		t.Error("review opened twice in a day")
+	}
+	if tl := newTimerList(cfg, timerSetup{}, m); tl.review != nil {
+		t.Error("review opened again on a later launch the same day")
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// recurrence is the part of an RFC 5545 RRULE the timer follows: a
+// frequency with an interval, bounded by COUNT or UNTIL, and for weekly
+// rules the days of the week. Rules using anything else are rejected.
+type recurrence struct {
+	freq     string // DAILY, WEEKLY, MONTHLY or YEARLY
+	interval int
+	count    int       // 0 for no limit
+	until    time.Time // zero for no limit
+	byDay    []time.Weekday
+}
+
+var icsWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+func parseRRule(value string) (*recurrence, error) {
+	r := &recurrence{interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		k, v, _ := strings.Cut(part, "=")
+		var err error
+		switch strings.ToUpper(k) {
+		case "FREQ":
+			switch v = strings.ToUpper(v); v {
+			case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+				r.freq = v
+			default:
+				return nil, fmt.Errorf("FREQ=%s is not supported", v)
+			}
+		case "INTERVAL":
+			if r.interval, err = strconv.Atoi(v); err != nil || r.interval < 1 {
+				return nil, fmt.Errorf("INTERVAL=%s is not a positive number", v)
+			}
+		case "COUNT":
+			if r.count, err = strconv.Atoi(v); err != nil || r.count < 1 {
+				return nil, fmt.Errorf("COUNT=%s is not a positive number", v)
+			}
+		case "UNTIL":
+			var allDay bool
+			if r.until, allDay, err = parseICSTime(v, ""); err != nil {
+				return nil, fmt.Errorf("UNTIL=%s: %w", v, err)
+			}
+			if allDay {
+				// A date includes the whole day.
+				r.until = r.until.AddDate(0, 0, 1).Add(-time.Nanosecond)
+			}
+		case "BYDAY":
+			for _, d := range strings.Split(v, ",") {
+				wd, ok := icsWeekdays[strings.ToUpper(d)]
+				if !ok {
+					return nil, fmt.Errorf("BYDAY=%s is not supported", v)
+				}
+				r.byDay = append(r.byDay, wd)
+			}
+		case "WKST":
+		default:
+			return nil, fmt.Errorf("%s is not supported", k)
+		}
+	}
+	switch {
+	case r.freq == "":
+		return nil, errors.New("no FREQ")
+	case r.byDay != nil && r.freq != "WEEKLY":
+		return nil, fmt.Errorf("BYDAY with FREQ=%s is not supported", r.freq)
+	}
+	// Weeks start on Monday, the RFC's default.
+	sort.Slice(r.byDay, func(i, j int) bool { return (r.byDay[i]+6)%7 < (r.byDay[j]+6)%7 })
+	return r, nil
+}
+
+// startsIn returns the n-th period of the rule counted from first: one
+// start, or for weekly rules with BYDAY one per listed day of that week.
+// Months and years without first's day, such as February for the 30th,
+// have none.
+func (r *recurrence) startsIn(first time.Time, n int) []time.Time {
+	y, mo, d := first.Date()
+	h, mi, s := first.Clock()
+	at := func(y int, mo time.Month, d int) time.Time {
+		return time.Date(y, mo, d, h, mi, s, first.Nanosecond(), first.Location())
+	}
+	step := n * r.interval
+	switch r.freq {
+	case "DAILY":
+		return []time.Time{at(y, mo, d+step)}
+	case "WEEKLY":
+		if r.byDay == nil {
+			return []time.Time{at(y, mo, d+7*step)}
+		}
+		monday := d + 7*step - int(first.Weekday()+6)%7
+		starts := make([]time.Time, len(r.byDay))
+		for i, wd := range r.byDay {
+			starts[i] = at(y, mo, monday+int(wd+6)%7)
+		}
+		return starts
+	case "MONTHLY":
+		if t := at(y, mo+time.Month(step), d); t.Day() == d {
+			return []time.Time{t}
+		}
+	case "YEARLY":
+		if t := at(y+step, mo, d); t.Day() == d {
+			return []time.Time{t}
+		}
+	}
+	return nil
+}
+
+// occurrences returns the instances of e that overlap [from, to). An
+// event without a rule is its only instance.
+func (e calEvent) occurrences(from, to time.Time) []calEvent {
+	overlaps := func(start, end time.Time) bool { return start.Before(to) && end.After(from) }
+	if e.rule == nil {
+		if overlaps(e.start, e.end) {
+			return []calEvent{e}
+		}
+		return nil
+	}
+
+	length := e.end.Sub(e.start)
+	var out []calEvent
+	seen := 0
+	for n := 0; ; n++ {
+		for _, start := range e.rule.startsIn(e.start, n) {
+			switch {
+			case start.Before(e.start):
+				continue
+			case e.rule.count > 0 && seen == e.rule.count,
+				!e.rule.until.IsZero() && start.After(e.rule.until),
+				!start.Before(to):
+				return out
+			}
+			seen++
+			if e.excluded(start) {
+				continue
+			}
+			if end := start.Add(length); overlaps(start, end) {
+				inst := e
+				inst.start, inst.end, inst.rule, inst.exdates = start, end, nil, nil
+				out = append(out, inst)
+			}
+		}
+	}
+}
+
+func (e calEvent) excluded(start time.Time) bool {
+	for _, x := range e.exdates {
+		if x.Equal(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// expandEvents replaces each recurring event with its instances in
+// [from, to), leaving out those that a RECURRENCE-ID event with the same
+// UID moved or edited; that event stands in for them instead.
+func expandEvents(events []calEvent, from, to time.Time) []calEvent {
+	moved := map[string]bool{}
+	for _, e := range events {
+		if !e.recurrenceID.IsZero() {
+			moved[e.uid+"\x00"+e.recurrenceID.UTC().Format(time.RFC3339)] = true
+		}
+	}
+	var out []calEvent
+	for _, e := range events {
+		for _, inst := range e.occurrences(from, to) {
+			if e.rule != nil && moved[e.uid+"\x00"+inst.start.UTC().Format(time.RFC3339)] {
+				continue
+			}
+			out = append(out, inst)
+		}
+	}
+	return out
+}
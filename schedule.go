@@ -0,0 +1,320 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/codytheroux96/progress-timer/timer"
+)
+
+// schedule is a recurring timer from the config, written as
+// "<days> <HH:MM> [skip|late] <duration> [label]", e.g.
+// "weekdays 09:00 50m Focus".
+type schedule struct {
+	days     [7]bool // indexed by time.Weekday
+	workdays bool    // also skip holidays
+	hour     int
+	minute   int
+	fireLate bool // start a missed trigger on wake-up instead of skipping it
+	duration time.Duration
+	label    string
+	date     string // YYYY-MM-DD for one-off blocks from a plan
+}
+
+// missedGrace is how late a trigger may be noticed and still count as on
+// time, whatever its catch-up policy.
+const missedGrace = time.Minute
+
+var dayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// parseDays reads a day class or a list of weekdays. Workdays are Monday
+// to Friday minus holidays.
+func parseDays(s string) (days [7]bool, workdays bool, err error) {
+	switch strings.TrimSuffix(strings.ToLower(s), "s") {
+	case "daily":
+		return [7]bool{true, true, true, true, true, true, true}, false, nil
+	case "weekday":
+		return [7]bool{false, true, true, true, true, true, false}, false, nil
+	case "workday":
+		return [7]bool{false, true, true, true, true, true, false}, true, nil
+	case "weekend":
+		return [7]bool{true, false, false, false, false, false, true}, false, nil
+	}
+	for _, d := range strings.Split(strings.ToLower(s), ",") {
+		wd, ok := dayNames[d]
+		if !ok {
+			return days, false, fmt.Errorf("unknown day %q (use daily, weekdays, workdays, weekends or mon,tue,…)", d)
+		}
+		days[wd] = true
+	}
+	return days, false, nil
+}
+
+// holidaySet holds dates as YYYY-MM-DD.
+type holidaySet map[string]bool
+
+func (h holidaySet) has(t time.Time) bool { return h[t.Format(time.DateOnly)] }
+
+func parseHolidays(v string) (holidaySet, error) {
+	h := holidaySet{}
+	for _, f := range strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' }) {
+		d, err := time.Parse(time.DateOnly, f)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a date (YYYY-MM-DD)", f)
+		}
+		h[d.Format(time.DateOnly)] = true
+	}
+	return h, nil
+}
+
+// loadHolidays merges schedule.holidays with the all-day events of
+// schedule.holiday_calendar, a local .ics file or an http(s) feed.
+// Recurring holidays are expanded from a year ago to two years ahead,
+// more than a schedule looks.
+func (c config) loadHolidays() (holidaySet, error) {
+	h := holidaySet{}
+	for d := range c.holidays {
+		h[d] = true
+	}
+	if c.holidayCalendar == "" {
+		return h, nil
+	}
+
+	var events []calEvent
+	var err error
+	if isCalDAVURL(c.holidayCalendar) {
+		events, err = fetchICS(c.holidayCalendar)
+	} else {
+		events, err = readICSFile(c.holidayCalendar)
+	}
+	if err != nil {
+		return h, fmt.Errorf("%s: %w", c.holidayCalendar, err)
+	}
+	now := time.Now()
+	for _, e := range expandEvents(events, now.AddDate(-1, 0, 0), now.AddDate(2, 0, 0)) {
+		if !e.allDay {
+			continue
+		}
+		for d := e.start; d.Before(e.end); d = d.AddDate(0, 0, 1) {
+			h[d.Format(time.DateOnly)] = true
+		}
+	}
+	return h, nil
+}
+
+func parseSchedule(s string) (schedule, error) {
+	fields := strings.Fields(s)
+	if len(fields) < 3 {
+		return schedule{}, fmt.Errorf("%q should look like \"weekdays 09:00 50m Focus\"", s)
+	}
+
+	var sc schedule
+	var err error
+	if sc.days, sc.workdays, err = parseDays(fields[0]); err != nil {
+		return sc, err
+	}
+	at, err := time.Parse("15:04", fields[1])
+	if err != nil {
+		return sc, fmt.Errorf("%q is not a time of day (HH:MM)", fields[1])
+	}
+	sc.hour, sc.minute = at.Hour(), at.Minute()
+	fields = fields[2:]
+	switch fields[0] {
+	case "late":
+		sc.fireLate = true
+		fields = fields[1:]
+	case "skip":
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return sc, fmt.Errorf("%q is missing a duration", s)
+	}
+	if sc.duration, err = parseDuration(fields[0]); err != nil {
+		return sc, fmt.Errorf("%q is not a duration", fields[0])
+	}
+	sc.label = strings.Join(fields[1:], " ")
+	return sc, nil
+}
+
+// next returns the first trigger strictly after t, in t's location.
+func (sc schedule) next(t time.Time, holidays holidaySet) time.Time {
+	y, mo, d := t.Date()
+	// A year covers any run of holidays worth scheduling around.
+	for i := 0; i <= 366; i++ {
+		at := time.Date(y, mo, d+i, sc.hour, sc.minute, 0, 0, t.Location())
+		if sc.date != "" && at.Format(time.DateOnly) != sc.date {
+			continue
+		}
+		if at.After(t) && sc.days[at.Weekday()] && !(sc.workdays && holidays.has(at)) {
+			return at
+		}
+	}
+	return time.Time{}
+}
+
+// due reports whether sc triggered in (last, now] and should start now:
+// on-time triggers always fire, ones missed while asleep or busy only
+// under the late policy. Several missed triggers collapse into one.
+func (sc schedule) due(last, now time.Time, holidays holidaySet) bool {
+	var latest time.Time
+	for at := sc.next(last, holidays); !at.IsZero() && !at.After(now); at = sc.next(at, holidays) {
+		latest = at
+	}
+	if latest.IsZero() {
+		return false
+	}
+	return sc.fireLate || now.Sub(latest) <= missedGrace
+}
+
+type namedSchedule struct {
+	key string
+	sc  schedule
+}
+
+// allSchedules returns the configured schedules followed by the blocks of
+// today's plan.
+func allSchedules(c config, plan []schedule) []namedSchedule {
+	var list []namedSchedule
+	for i, sc := range c.schedules {
+		if sc != nil {
+			list = append(list, namedSchedule{fmt.Sprintf("schedule.%d", i), *sc})
+		}
+	}
+	for i, sc := range plan {
+		list = append(list, namedSchedule{fmt.Sprintf("plan.%d", i+1), sc})
+	}
+	return list
+}
+
+type upcoming struct {
+	at time.Time
+	namedSchedule
+}
+
+// upcomingSchedules lists each schedule's next trigger after now, soonest
+// first.
+func upcomingSchedules(list []namedSchedule, holidays holidaySet, now time.Time) []upcoming {
+	var out []upcoming
+	for _, ns := range list {
+		if at := ns.sc.next(now, holidays); !at.IsZero() {
+			out = append(out, upcoming{at, ns})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].at.Before(out[j].at) })
+	return out
+}
+
+func runScheduleCommand(args []string) int {
+	if len(args) > 1 || len(args) == 1 && args[0] != "run" {
+		return usage("schedule")
+	}
+
+	cfg, err := loadConfig(configFile())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitInvalid
+	}
+	holidays, err := cfg.loadHolidays()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitInvalid
+	}
+	now := time.Now()
+	schedules := allSchedules(cfg, loadPlan(now))
+	if len(args) == 1 {
+		return runScheduler(cfg, timer.SystemClock{}, schedules, holidays)
+	}
+
+	list := upcomingSchedules(schedules, holidays, now)
+	if len(list) == 0 {
+		fmt.Println("No schedules configured. Add schedule.1 = weekdays 09:00 50m Focus to", configFile())
+		return 0
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, u := range list {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", u.at.Format("Mon Jan 2 15:04"), formatDuration(u.sc.duration), u.sc.label, u.key)
+	}
+	w.Flush()
+	return 0
+}
+
+// scheduler remembers when each schedule was last checked, so a trigger
+// that passes between two checks is still noticed.
+type scheduler struct {
+	schedules []namedSchedule
+	holidays  holidaySet
+	checked   []time.Time
+}
+
+func newScheduler(schedules []namedSchedule, holidays holidaySet, now time.Time) *scheduler {
+	s := &scheduler{schedules: schedules, holidays: holidays, checked: make([]time.Time, len(schedules))}
+	for i := range s.checked {
+		s.checked[i] = now
+	}
+	return s
+}
+
+// check reports whether schedule i should start at now.
+func (s *scheduler) check(i int, now time.Time) bool {
+	due := s.schedules[i].sc.due(s.checked[i], now, s.holidays)
+	s.checked[i] = now
+	return due
+}
+
+// runScheduler waits for schedules in the foreground and runs each
+// triggered timer in the terminal, then goes back to waiting.
+func runScheduler(cfg config, clk clock, schedules []namedSchedule, holidays holidaySet) int {
+	if len(upcomingSchedules(schedules, holidays, clk.Now())) == 0 {
+		fmt.Fprintln(os.Stderr, "no schedules configured or planned")
+		return exitInvalid
+	}
+
+	opts := cfg.programOptions()
+
+	var shown upcoming
+	s := newScheduler(schedules, holidays, clk.Now())
+	for {
+		for i, ns := range schedules {
+			if !s.check(i, clk.Now()) {
+				continue
+			}
+			// Triggers that pass while this timer runs count as missed.
+			sc := ns.sc
+			m := initialModel(cfg)
+			m.clock = clk
+			m = m.begin(sc.duration)
+			m.label = sc.label
+			m.exitOnComplete = true
+			final, err := tea.NewProgram(m, opts...).Run()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", ns.key, err)
+				return exitError
+			}
+			logUnfinished(final.(model))
+			os.Stdout.WriteString(cfg.reporter.clearSequence())
+		}
+
+		now := clk.Now()
+		pending := upcomingSchedules(schedules, holidays, now)
+		if len(pending) == 0 {
+			fmt.Println("Nothing left to run.")
+			return exitCompleted
+		}
+		next := pending[0]
+		if next.key != shown.key || !next.at.Equal(shown.at) {
+			fmt.Printf("Next: %s at %s\n", next.key, next.at.Format("Mon 15:04"))
+			shown = next
+		}
+		// Sleep in short steps: timers stop during system suspend, so a
+		// long wait would oversleep a wake-up.
+		time.Sleep(min(next.at.Sub(now), missedGrace))
+	}
+}
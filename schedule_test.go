@@ -0,0 +1,102 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func mustSchedule(t *testing.T, s string) schedule {
+	t.Helper()
+	sc, err := parseSchedule(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sc
+}
+
+// monday is 2026-03-02, a Monday.
+func monday(hour, minute int) time.Time {
+	return time.Date(2026, 3, 2, hour, minute, 0, 0, time.UTC)
+}
+
+func TestSchedulerCatchUp(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule string
+		holidays string
+		checked  time.Time
+		now      time.Time
+		due      bool
+	}{
+		{"on time", "daily 09:00 50m", "", monday(8, 59), monday(9, 0).Add(10 * time.Second), true},
+		{"within the grace period", "daily 09:00 50m", "", monday(8, 59), monday(9, 1), true},
+		{"not yet", "daily 09:00 50m", "", monday(8, 58), monday(8, 59), false},
+		{"missed, skipped", "daily 09:00 50m", "", monday(8, 0), monday(10, 0), false},
+		{"missed, started late", "daily 09:00 late 50m", "", monday(8, 0), monday(10, 0), true},
+		{"missed for days, started once", "daily 09:00 late 50m", "", monday(8, 0), monday(10, 0).AddDate(0, 0, 2), true},
+		{"not today", "sat,sun 09:00 late 50m", "", monday(8, 0), monday(10, 0), false},
+		{"holiday", "workdays 09:00 50m", "2026-03-02", monday(8, 59), monday(9, 0), false},
+		{"weekday on a holiday", "weekdays 09:00 50m", "2026-03-02", monday(8, 59), monday(9, 0), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			holidays, err := parseHolidays(tt.holidays)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := newScheduler([]namedSchedule{{"schedule.1", mustSchedule(t, tt.schedule)}}, holidays, tt.checked)
+			if got := s.check(0, tt.now); got != tt.due {
+				t.Fatalf("due %v, want %v", got, tt.due)
+			}
+			if s.check(0, tt.now.Add(time.Second)) {
+				t.Error("due again on the next check")
+			}
+		})
+	}
+}
+
+// TestSchedulerWaking drives the scheduler the way runScheduler does, a
+// check a minute apart, across a suspend that swallows the 09:00 trigger.
+func TestSchedulerWaking(t *testing.T) {
+	clk := &fakeClock{now: monday(8, 0)}
+	s := newScheduler([]namedSchedule{
+		{"schedule.1", mustSchedule(t, "daily 09:00 50m skip")},
+		{"schedule.2", mustSchedule(t, "daily 09:00 late 50m")},
+		{"schedule.3", mustSchedule(t, "daily 11:00 25m")},
+	}, nil, clk.Now())
+
+	var started []string
+	check := func() {
+		for i, ns := range s.schedules {
+			if s.check(i, clk.Now()) {
+				started = append(started, ns.key+"@"+clk.Now().Format("15:04"))
+			}
+		}
+	}
+	check()
+	clk.advance(2 * time.Hour) // asleep from 08:00 to 10:00
+	check()
+	for clk.Now().Before(monday(11, 30)) {
+		clk.advance(time.Minute)
+		check()
+	}
+
+	want := []string{"schedule.2@10:00", "schedule.3@11:00"}
+	if len(started) != len(want) {
+		t.Fatalf("started %v, want %v", started, want)
+	}
+	for i := range want {
+		if started[i] != want[i] {
+			t.Fatalf("started %v, want %v", started, want)
+		}
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	sc := mustSchedule(t, "weekdays 09:00 50m")
+	friday := time.Date(2026, 3, 6, 10, 0, 0, 0, time.UTC)
+	want := time.Date(2026, 3, 9, 9, 0, 0, 0, time.UTC)
+	if got := sc.next(friday, nil); !got.Equal(want) {
+		t.Errorf("next after Friday 10:00 = %s, want %s", got, want)
+	}
+}
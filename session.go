@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Named sessions accumulate time across sittings, one per line in the
+// state directory as "<seconds>\t<name>".
+
+func sessionsFile() string {
+	return filepath.Join(stateDir(), "sessions")
+}
+
+func loadSessions() map[string]time.Duration {
+	return loadTotals(sessionsFile())
+}
+
+func saveSessions(sessions map[string]time.Duration) error {
+	return saveTotals(sessionsFile(), sessions)
+}
+
+// loadTotals reads a "<seconds>\t<name>" file from the state directory.
+func loadTotals(path string) map[string]time.Duration {
+	totals := map[string]time.Duration{}
+	f, err := os.Open(path)
+	if err != nil {
+		return totals
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		secs, name, ok := strings.Cut(sc.Text(), "\t")
+		n, err := strconv.ParseInt(secs, 10, 64)
+		if ok && err == nil && name != "" {
+			totals[name] = time.Duration(n) * time.Second
+		}
+	}
+	return totals
+}
+
+func saveTotals(path string, totals map[string]time.Duration) error {
+	if err := os.MkdirAll(stateDir(), 0o755); err != nil {
+		return err
+	}
+	names := make([]string, 0, len(totals))
+	for name := range totals {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%d\t%s\n", int64(totals[name].Seconds()), name)
+	}
+	return os.WriteFile(path, []byte(b.String()), 0o644)
+}
+
+// addSessionTime records a finished sitting of the named session.
+func addSessionTime(name string, d time.Duration) error {
+	sessions := loadSessions()
+	sessions[name] += d.Round(time.Second)
+	return saveSessions(sessions)
+}
+
+// sittingTime is how long the timer has been running, overtime included.
+func (m model) sittingTime() time.Duration {
+	if m.state == inputtingTime {
+		return 0
+	}
+	return m.countdown.Duration - m.timeRemaining + m.overrun
+}
+
+func runSessionsCommand(args []string) int {
+	if len(args) > 0 {
+		return usage("sessions")
+	}
+	sessions := loadSessions()
+	if len(sessions) == 0 {
+		fmt.Println("No named sessions yet. Start one with --session NAME.")
+		return 0
+	}
+	names := make([]string, 0, len(sessions))
+	for name := range sessions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, name := range names {
+		fmt.Fprintf(w, "%s\t%s\n", formatDuration(sessions[name]), name)
+	}
+	w.Flush()
+	return 0
+}
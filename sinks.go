@@ -0,0 +1,373 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// progressEvent is what the sinks are sent: on start, every second, on
+// pause and resume, and when the timer completes or is cancelled.
+type progressEvent struct {
+	Event     string    `json:"event"` // started, tick, paused, resumed, completed or cancelled
+	Time      time.Time `json:"time"`
+	Label     string    `json:"label,omitempty"`
+	Phase     string    `json:"phase,omitempty"`
+	Duration  int       `json:"duration"`  // seconds
+	Remaining int       `json:"remaining"` // seconds
+	Percent   float64   `json:"percent"`   // 0 to 100
+}
+
+func (m model) progressEvent(kind string) progressEvent {
+	return progressEvent{
+		Event:     kind,
+		Time:      m.clock.Now(),
+		Label:     m.label,
+		Phase:     m.phaseName(),
+		Duration:  int(m.countdown.Duration.Seconds()),
+		Remaining: int(m.timeRemaining.Seconds()),
+		Percent:   math.Round(m.percent()*1000) / 10,
+	}
+}
+
+// publish tells the sinks what just happened. It is called where it
+// happens rather than from a command, so the events arrive in order.
+func (m model) publish(kind string) {
+	for _, s := range m.sinks {
+		s.send(m.progressEvent(kind))
+	}
+}
+
+// A sink reports the timer's progress somewhere besides the TUI. Any
+// number can be active at once. send must not block.
+type sink interface {
+	send(e progressEvent)
+	close()
+}
+
+type sinks []sink
+
+func (s sinks) close() {
+	for _, k := range s {
+		k.close()
+	}
+}
+
+// jsonSink writes one JSON object per line, for --output json.
+type jsonSink struct {
+	enc *json.Encoder
+}
+
+func newJSONSink(w io.Writer) jsonSink {
+	return jsonSink{json.NewEncoder(w)}
+}
+
+func (s jsonSink) send(e progressEvent) { _ = s.enc.Encode(e) }
+func (s jsonSink) close()               {}
+
+// fileSink keeps the latest event in a file, replaced whole each time so
+// a reader never sees half of one. The writing happens in the background,
+// so a slow disk holds up the file rather than the timer; events it
+// hasn't got to yet are skipped for the latest.
+type fileSink struct {
+	path    string
+	pending chan []byte // the latest event not yet written, if any
+	done    chan struct{}
+	mu      sync.Mutex
+	closed  bool
+}
+
+func newFileSink(path string) *fileSink {
+	s := &fileSink{path: path, pending: make(chan []byte, 1), done: make(chan struct{})}
+	go s.write()
+	return s
+}
+
+func (s *fileSink) send(e progressEvent) {
+	data, _ := json.Marshal(e)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	for {
+		select {
+		case s.pending <- data:
+			return
+		default:
+		}
+		select {
+		case <-s.pending:
+		default:
+		}
+	}
+}
+
+func (s *fileSink) write() {
+	defer close(s.done)
+	for data := range s.pending {
+		tmp := s.path + ".tmp"
+		if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err == nil {
+			_ = os.Rename(tmp, s.path)
+		}
+	}
+}
+
+// close waits for the last event to be written.
+func (s *fileSink) close() {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.pending)
+	}
+	s.mu.Unlock()
+	<-s.done
+}
+
+// broadcast hands each event to every listener, starting with the latest
+// one. A listener that falls behind misses events rather than holding up
+// the timer. Once finishing, it takes no new listeners or events.
+type broadcast struct {
+	mu        sync.Mutex
+	last      []byte
+	listeners map[chan []byte]bool
+	wg        sync.WaitGroup // listeners still writing
+	finishing bool
+}
+
+func (b *broadcast) send(e progressEvent) {
+	data, _ := json.Marshal(e)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.finishing {
+		return
+	}
+	b.last = data
+	for ch := range b.listeners {
+		select {
+		case ch <- data:
+		default:
+		}
+	}
+}
+
+func (b *broadcast) listen() (<-chan []byte, func()) {
+	ch := make(chan []byte, 16)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.finishing {
+		close(ch)
+		return ch, func() {}
+	}
+	if b.listeners == nil {
+		b.listeners = map[chan []byte]bool{}
+	}
+	b.listeners[ch] = true
+	b.wg.Add(1)
+	if b.last != nil {
+		ch <- b.last
+	}
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.listeners, ch)
+		b.wg.Done()
+	}
+}
+
+// finish ends every listener's stream once it has written what it was
+// sent, so the last event isn't lost when the program exits.
+func (b *broadcast) finish() {
+	b.mu.Lock()
+	b.finishing = true
+	for ch := range b.listeners {
+		close(ch)
+	}
+	b.mu.Unlock()
+	b.wg.Wait()
+}
+
+// socketSink streams the events as JSON lines to whoever connects to a
+// Unix socket, e.g. socat - UNIX-CONNECT:PATH.
+type socketSink struct {
+	*broadcast
+	path string
+	ln   net.Listener
+}
+
+func newSocketSink(path string) (*socketSink, error) {
+	// A socket left behind by a crash would make Listen fail.
+	if st, err := os.Lstat(path); err == nil && st.Mode()&os.ModeSocket != 0 {
+		os.Remove(path)
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	s := &socketSink{broadcast: &broadcast{}, path: path, ln: ln}
+	go s.serve()
+	return s, nil
+}
+
+func (s *socketSink) serve() {
+	for {
+		c, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer c.Close()
+			events, stop := s.listen()
+			defer stop()
+			for data := range events {
+				c.SetWriteDeadline(time.Now().Add(time.Second))
+				if _, err := c.Write(append(data, '\n')); err != nil {
+					return
+				}
+			}
+		}()
+	}
+}
+
+func (s *socketSink) close() {
+	s.ln.Close()
+	s.finish()
+	os.Remove(s.path)
+}
+
+// overlayPage is the web overlay: the label, the remaining time and a bar
+// on a transparent background, for a streaming program's browser source.
+const overlayPage = `<!doctype html>
+<meta charset="utf-8">
+<title>progress-timer</title>
+<style>
+body { margin: 0; background: transparent; color: #fff; font: bold 32px sans-serif; text-shadow: 0 0 4px #000; }
+#bar { height: 12px; background: rgba(0,0,0,.4); margin-top: 8px; }
+#fill { height: 100%; width: 0; background: #00af00; transition: width 1s linear; }
+</style>
+<div id="label"></div>
+<div id="time"></div>
+<div id="bar"><div id="fill"></div></div>
+<script>
+const pad = n => String(n).padStart(2, "0");
+const clock = s => (s >= 3600 ? Math.floor(s / 3600) + ":" : "") + pad(Math.floor(s / 60) % 60) + ":" + pad(s % 60);
+new EventSource("/events").onmessage = m => {
+  const e = JSON.parse(m.data);
+  document.getElementById("label").textContent = [e.label, e.phase].filter(Boolean).join(" · ");
+  document.getElementById("time").textContent = e.event === "completed" ? "Done" : clock(e.remaining);
+  document.getElementById("fill").style.width = e.percent + "%";
+};
+</script>
+`
+
+// webSink serves the overlay page, the events as server-sent events on
+// /events and the latest one on /status.
+type webSink struct {
+	*broadcast
+	srv *http.Server
+	ln  net.Listener
+}
+
+func newWebSink(addr string) (*webSink, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &webSink{broadcast: &broadcast{}, ln: ln}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, overlayPage)
+	})
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		last := s.last
+		s.mu.Unlock()
+		if last == nil {
+			http.Error(w, "no timer yet", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(last)
+	})
+	mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		events, stop := s.listen()
+		defer stop()
+		for {
+			select {
+			case data, ok := <-events:
+				if !ok {
+					return
+				}
+				fmt.Fprintf(w, "data: %s\n\n", data)
+				flusher.Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	})
+	s.srv = &http.Server{Handler: mux}
+	go s.srv.Serve(ln)
+	return s, nil
+}
+
+// close stops taking connections, lets the open streams write what they
+// were sent, and then shuts the server.
+func (s *webSink) close() {
+	s.ln.Close()
+	s.finish()
+	s.srv.Close()
+}
+
+// sinkFlags are the sinks asked for on the command line.
+type sinkFlags struct {
+	json         bool
+	progressFile string
+	socket       string
+	web          string
+}
+
+// open starts the sinks. If one fails, those already started are closed.
+func (f sinkFlags) open() (sinks, error) {
+	var out sinks
+	if f.json {
+		out = append(out, newJSONSink(os.Stdout))
+	}
+	if f.progressFile != "" {
+		if err := os.MkdirAll(filepath.Dir(f.progressFile), 0o755); err != nil {
+			return nil, err
+		}
+		out = append(out, newFileSink(f.progressFile))
+	}
+	if f.socket != "" {
+		s, err := newSocketSink(f.socket)
+		if err != nil {
+			out.close()
+			return nil, fmt.Errorf("--status-socket: %w", err)
+		}
+		out = append(out, s)
+	}
+	if f.web != "" {
+		s, err := newWebSink(f.web)
+		if err != nil {
+			out.close()
+			return nil, fmt.Errorf("--web-overlay: %w", err)
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
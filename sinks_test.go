@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileSinkKeepsLatest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "progress.json")
+	s := newFileSink(path)
+	for i := range 100 {
+		s.send(progressEvent{Event: "tick", Remaining: 100 - i})
+	}
+	s.send(progressEvent{Event: "completed"})
+	s.close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var e progressEvent
+	if err := json.Unmarshal(data, &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Event != "completed" || e.Remaining != 0 {
+		t.Errorf("file holds %+v, want the completed event", e)
+	}
+}
+
+func TestFileSinkSendAfterClose(t *testing.T) {
+	s := newFileSink(filepath.Join(t.TempDir(), "progress.json"))
+	s.close()
+	s.send(progressEvent{Event: "cancelled"})
+	s.close()
+}
+
+func TestBroadcastFinish(t *testing.T) {
+	var b broadcast
+	early, stop := b.listen()
+	b.send(progressEvent{Event: "tick"})
+	go func() {
+		for range early {
+		}
+		stop()
+	}()
+	b.finish()
+
+	late, _ := b.listen()
+	if _, ok := <-late; ok {
+		t.Error("a listener after finish got an event")
+	}
+	b.send(progressEvent{Event: "completed"})
+}
+
+func TestProgressEventTime(t *testing.T) {
+	m, clk := startedModel(t, "1")
+	m, _ = m.tick(clk.advance(15 * time.Second))
+	e := m.progressEvent("tick")
+	if !e.Time.Equal(clk.Now()) || e.Remaining != 45 || e.Percent != 25 {
+		t.Errorf("got %+v, want the fake clock's time, 45s left, 25%%", e)
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// alertRepeat is how often an unacknowledged alert sounds again under
+// sound.repeat.
+const alertRepeat = 5 * time.Second
+
+// player plays a sound file to completion.
+type player interface {
+	play(file string) error
+}
+
+// commandPlayer runs a shell command with the file appended.
+type commandPlayer string
+
+func (p commandPlayer) play(file string) error {
+	return shellCommand(string(p), file).Run()
+}
+
+// execPlayer runs a known audio tool directly.
+type execPlayer []string
+
+func (p execPlayer) play(file string) error {
+	return exec.Command(p[0], append(p[1:], file)...).Run()
+}
+
+// mediaPlayerScript uses .NET's SoundPlayer, which only handles wav files.
+// The path comes in through the environment to avoid quoting it.
+const mediaPlayerScript = `(New-Object Media.SoundPlayer $env:PT_SOUND).PlaySync()`
+
+type mediaPlayer struct{}
+
+func (mediaPlayer) play(file string) error {
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", mediaPlayerScript)
+	cmd.Env = append(os.Environ(), "PT_SOUND="+file)
+	return cmd.Run()
+}
+
+// soundPlayers are tried in order until one is installed.
+var soundPlayers = [][]string{
+	{"paplay"},
+	{"pw-play"},
+	{"aplay", "-q"},
+	{"ffplay", "-nodisp", "-autoexit", "-loglevel", "quiet"},
+	{"mpv", "--no-video", "--really-quiet"},
+}
+
+// player returns the configured sound.player, or else a platform default.
+// It is nil without a sound file or a way to play it, and over SSH, where
+// the sound would play on the remote machine.
+func (c config) player() player {
+	escalates := slices.ContainsFunc(c.escalation, func(s escalationStep) bool { return s.soundFile != "" })
+	if c.soundFile == "" && !escalates || c.isRemote() {
+		return nil
+	}
+	if c.soundPlayer != "" {
+		return commandPlayer(c.soundPlayer)
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return execPlayer{"afplay"}
+	case "windows":
+		return mediaPlayer{}
+	}
+	for _, p := range soundPlayers {
+		if _, err := exec.LookPath(p[0]); err == nil {
+			return execPlayer(p)
+		}
+	}
+	return nil
+}
+
+// soundAlert rings the bell and plays the sound file, as configured.
+func (m model) soundAlert() tea.Cmd {
+	var cmds []tea.Cmd
+	if m.bell {
+		cmds = append(cmds, writeTerminal("\a"))
+	}
+	if m.player != nil && m.alertSound != "" {
+		p, file := m.player, m.alertSound
+		cmds = append(cmds, func() tea.Msg {
+			_ = p.play(file)
+			return nil
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// startAlert sounds the completion alert and, under sound.repeat or
+// alert.escalation, keeps it going until a key is pressed.
+func (m model) startAlert() (model, tea.Cmd) {
+	m.alertingFor = 0
+	m.alerting = m.repeatAlert || len(m.escalation) > 0
+	m.alertSound = m.soundFile
+	m.alertBody = m.completionMessage()
+	return m, m.soundAlert()
+}
+
+// alertTick takes the escalation steps that are due and repeats the
+// alert every alertRepeat under sound.repeat, while unacknowledged.
+func (m model) alertTick() (model, tea.Cmd) {
+	if !m.alerting {
+		return m, nil
+	}
+	m.alertingFor += time.Second
+	var cmds []tea.Cmd
+	for _, s := range m.escalation {
+		if s.after == m.alertingFor {
+			var cmd tea.Cmd
+			m, cmd = m.escalate(s)
+			cmds = append(cmds, cmd)
+		}
+	}
+	if m.repeatAlert && m.alertingFor%alertRepeat == 0 {
+		cmds = append(cmds, m.soundAlert())
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// escalationStep is one step of alert.escalation: what to do once an
+// alert has gone unacknowledged for after.
+type escalationStep struct {
+	after     time.Duration
+	bell      bool
+	sound     bool
+	soundFile string // the louder sound, if the step has one
+	notify    bool
+	hook      bool
+}
+
+// parseEscalation reads steps such as "1m sound=loud.wav, 3m notify+hook".
+func parseEscalation(v string) ([]escalationStep, error) {
+	var steps []escalationStep
+	for _, f := range strings.Split(v, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		after, actions, ok := strings.Cut(f, " ")
+		if !ok {
+			return nil, fmt.Errorf("%q should look like <after> <actions>, e.g. 3m notify+hook", f)
+		}
+		d, err := time.ParseDuration(after)
+		if err != nil || d < time.Second || d%time.Second != 0 {
+			return nil, fmt.Errorf("%q is not a whole number of seconds, e.g. 90s or 3m", after)
+		}
+		s := escalationStep{after: d}
+		for _, a := range strings.Split(strings.TrimSpace(actions), "+") {
+			name, file, _ := strings.Cut(strings.TrimSpace(a), "=")
+			switch {
+			case name == "bell" && file == "":
+				s.bell = true
+			case name == "sound":
+				s.sound = true
+				if file != "" {
+					if s.soundFile, err = checkSoundFile(file); err != nil {
+						return nil, err
+					}
+				}
+			case name == "notify" && file == "":
+				s.notify = true
+			case name == "hook" && file == "":
+				s.hook = true
+			default:
+				return nil, fmt.Errorf("%q should be bell, sound, sound=FILE, notify or hook", a)
+			}
+		}
+		steps = append(steps, s)
+	}
+	return steps, nil
+}
+
+// escalate takes step s. A louder sound it plays is the one later
+// repeats play.
+func (m model) escalate(s escalationStep) (model, tea.Cmd) {
+	var cmds []tea.Cmd
+	if s.soundFile != "" {
+		m.alertSound = s.soundFile
+	}
+	if s.sound {
+		cmds = append(cmds, m.soundAlert())
+	}
+	if s.bell {
+		cmds = append(cmds, writeTerminal("\a"))
+	}
+	if s.notify {
+		body := fmt.Sprintf("Unacknowledged for %s. %s", formatDuration(s.after), m.alertBody)
+		cmds = append(cmds, m.desktopNotify(fmt.Sprintf("escalation after %s", s.after), body))
+	}
+	if s.hook {
+		cmds = append(cmds, m.eventHook(eventEscalate))
+	}
+	return m, tea.Batch(cmds...)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestParseEscalation(t *testing.T) {
+	loud := filepath.Join(t.TempDir(), "loud.wav")
+	if err := os.WriteFile(loud, []byte("RIFF"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	steps, err := parseEscalation("1m sound=" + loud + ", 3m notify+hook+bell")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 2 || steps[0].after != time.Minute || !steps[0].sound || steps[0].soundFile != loud ||
+		steps[1].after != 3*time.Minute || !steps[1].notify || !steps[1].hook || !steps[1].bell || steps[1].sound {
+		t.Errorf("got %+v", steps)
+	}
+	for _, bad := range []string{"1m", "soon notify", "1500ms notify", "1m shout", "1m notify=x", "1m sound=/no/such.wav"} {
+		if _, err := parseEscalation(bad); err == nil {
+			t.Errorf("%q was accepted", bad)
+		}
+	}
+}
+
+// recordPlayer keeps the files it is asked to play.
+type recordPlayer struct{ played *[]string }
+
+func (p recordPlayer) play(file string) error {
+	*p.played = append(*p.played, file)
+	return nil
+}
+
+func TestAlertEscalation(t *testing.T) {
+	m, _ := startedModel(t, "1")
+	var played []string
+	notified := 0
+	m.bell = false
+	m.player = recordPlayer{&played}
+	m.soundFile = "chime.wav"
+	m.notifier = countingNotifier{&notified}
+	m.repeatAlert = true
+	m.escalation = []escalationStep{
+		{after: 6 * time.Second, sound: true, soundFile: "loud.wav"},
+		{after: 8 * time.Second, notify: true},
+	}
+
+	m, cmd := m.startAlert()
+	runHeadlessCmd(cmd)
+	for range 10 {
+		m, cmd = m.alertTick()
+		runHeadlessCmd(cmd)
+	}
+	want := []string{"chime.wav", "chime.wav", "loud.wav", "loud.wav"} // start, 5s, step at 6s, 10s
+	if len(played) != len(want) {
+		t.Fatalf("played %v, want %v", played, want)
+	}
+	for i := range want {
+		if played[i] != want[i] {
+			t.Fatalf("played %v, want %v", played, want)
+		}
+	}
+	if notified != 1 {
+		t.Errorf("notified %d times, want once at 8s", notified)
+	}
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	m = next.(model)
+	for range 10 {
+		m, cmd = m.alertTick()
+		runHeadlessCmd(cmd)
+	}
+	if len(played) != len(want) {
+		t.Errorf("kept alerting after a key: played %v", played)
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	statsDays     = 14
+	statsLabels   = 8
+	statsBarWidth = 30
+)
+
+// stats summarises the history: time per day, streaks of days with a
+// completed timer, time per label, and when in the day and week the time
+// goes. Broken timers don't keep a streak.
+type stats struct {
+	total     time.Duration
+	completed int
+	broken    int
+	cancelled int
+	overran   int                      // finished timers that ran past their planned time
+	overrun   time.Duration            // by how much in total
+	perDay    map[string]time.Duration // keyed by YYYY-MM-DD
+	perLabel  map[string]time.Duration
+	// perHour and perWeekday count each timer in the hour and on the day
+	// it started, local time.
+	perHour    [24]time.Duration
+	perWeekday [7]time.Duration // indexed by time.Weekday, Sunday first
+	current    int
+	longest    int
+}
+
+// computeStats sums the history up together with the daily totals of the
+// timers pruned from it.
+func computeStats(entries []historyEntry, pruned []dailyTotal, now time.Time) stats {
+	s := stats{perDay: map[string]time.Duration{}, perLabel: map[string]time.Duration{}}
+	doneOn := map[string]bool{}
+	for _, t := range foldDaily(slices.Clone(pruned), entries, now.Location()) {
+		s.total += t.actual
+		s.perDay[t.day] += t.actual
+		s.perHour[t.hour] += t.actual
+		if day, err := time.ParseInLocation(time.DateOnly, t.day, now.Location()); err == nil {
+			s.perWeekday[day.Weekday()] += t.actual
+		}
+		label := t.label
+		if label == "" {
+			label = "(no label)"
+		}
+		s.perLabel[label] += t.actual
+		s.overran += t.overran
+		s.overrun += t.overrun
+		switch t.status {
+		case statusCompleted:
+			s.completed += t.count
+			doneOn[t.day] = true
+		case statusBroken:
+			s.broken += t.count
+		default:
+			s.cancelled += t.count
+		}
+	}
+
+	var days []string
+	for d := range doneOn {
+		days = append(days, d)
+	}
+	sort.Strings(days)
+	run := 0
+	var prev time.Time
+	for _, d := range days {
+		t, _ := time.ParseInLocation(time.DateOnly, d, now.Location())
+		if run > 0 && prev.AddDate(0, 0, 1).Equal(t) {
+			run++
+		} else {
+			run = 1
+		}
+		s.longest = max(s.longest, run)
+		prev = t
+	}
+	// The current streak may end yesterday: today isn't over yet.
+	y, mo, d := now.Date()
+	day := time.Date(y, mo, d, 0, 0, 0, 0, now.Location())
+	if !doneOn[day.Format(time.DateOnly)] {
+		day = day.AddDate(0, 0, -1)
+	}
+	for ; doneOn[day.Format(time.DateOnly)]; day = day.AddDate(0, 0, -1) {
+		s.current++
+	}
+	return s
+}
+
+// statsView is the stats screen; any key closes it.
+type statsView struct {
+	stats stats
+	now   time.Time
+	ascii bool
+}
+
+func (v statsView) Init() tea.Cmd { return nil }
+
+func (v statsView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if _, ok := msg.(tea.KeyMsg); ok {
+		return v, tea.Quit
+	}
+	return v, nil
+}
+
+func (v statsView) bar(d, scale time.Duration) string {
+	n := 0
+	if scale > 0 {
+		n = int(float64(statsBarWidth) * float64(d) / float64(scale))
+	}
+	fill := "█"
+	if v.ascii {
+		fill = "#"
+	}
+	return statusMessageStyle.Render(strings.Repeat(fill, n))
+}
+
+func (v statsView) View() string {
+	s := v.stats
+	bold := lipgloss.NewStyle().Bold(true)
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s %s in %d completed, %d broken and %d cancelled timers\n", bold.Render("Total"),
+		formatDuration(s.total), s.completed, s.broken, s.cancelled)
+	if finished := s.completed + s.broken; finished > 0 {
+		fmt.Fprintf(&b, "%s %d of %d finished timers, by %s in total\n", bold.Render("Overran"),
+			s.overran, finished, formatDuration(s.overrun))
+	}
+	fmt.Fprintf(&b, "%s %d days, longest %d\n\n", bold.Render("Streak"), s.current, s.longest)
+
+	b.WriteString(bold.Render(fmt.Sprintf("Last %d days", statsDays)) + "\n")
+	var scale time.Duration
+	for i := range statsDays {
+		scale = max(scale, s.perDay[v.now.AddDate(0, 0, -i).Format(time.DateOnly)])
+	}
+	for i := statsDays - 1; i >= 0; i-- {
+		day := v.now.AddDate(0, 0, -i)
+		d := s.perDay[day.Format(time.DateOnly)]
+		fmt.Fprintf(&b, "%-10s %8s %s\n", day.Format("Mon Jan 2"), formatDuration(d), v.bar(d, scale))
+	}
+
+	b.WriteString("\n" + bold.Render("By hour") + "\n")
+	first, last := -1, 0
+	var hourScale time.Duration
+	for h, d := range s.perHour {
+		if d > 0 {
+			if first < 0 {
+				first = h
+			}
+			last = h
+		}
+		hourScale = max(hourScale, d)
+	}
+	// Only the span of hours with any time, not all 24.
+	for h := max(first, 0); h <= last; h++ {
+		fmt.Fprintf(&b, "%-10s %8s %s\n", fmt.Sprintf("%02d:00", h), formatDuration(s.perHour[h]), v.bar(s.perHour[h], hourScale))
+	}
+
+	b.WriteString("\n" + bold.Render("By weekday") + "\n")
+	var weekScale time.Duration
+	for _, d := range s.perWeekday {
+		weekScale = max(weekScale, d)
+	}
+	for i := range 7 {
+		day := time.Weekday((i + 1) % 7) // Monday first
+		d := s.perWeekday[day]
+		fmt.Fprintf(&b, "%-10s %8s %s\n", day, formatDuration(d), v.bar(d, weekScale))
+	}
+
+	labels := make([]string, 0, len(s.perLabel))
+	for l := range s.perLabel {
+		labels = append(labels, l)
+	}
+	sort.Strings(labels)
+	sort.SliceStable(labels, func(i, j int) bool { return s.perLabel[labels[i]] > s.perLabel[labels[j]] })
+	if len(labels) > 0 {
+		b.WriteString("\n" + bold.Render("By label") + "\n")
+		for _, l := range labels[:min(len(labels), statsLabels)] {
+			fmt.Fprintf(&b, "%s %8s %s\n", placeText(l, 20), formatDuration(s.perLabel[l]), v.bar(s.perLabel[l], s.perLabel[labels[0]]))
+		}
+	}
+	b.WriteString("\nPress any key to close\n")
+	return lipgloss.NewStyle().Margin(1, 2).Render(b.String())
+}
+
+func runStatsCommand(args []string) int {
+	if len(args) > 0 {
+		return usage("stats")
+	}
+	cfg, err := loadConfig(configFile())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitInvalid
+	}
+	entries, pruned := loadHistory(), loadDaily()
+	if len(entries) == 0 && len(pruned) == 0 {
+		fmt.Println("No timers recorded yet.")
+		return 0
+	}
+	t := cfg.theme()
+	setStyles(t)
+	now := time.Now()
+	v := statsView{stats: computeStats(entries, pruned, now), now: now, ascii: t.asciiBar}
+	if _, err := tea.NewProgram(v, cfg.programOptions()...).Run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	return 0
+}
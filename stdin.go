@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// commandMsg is one line read in --stdin mode, e.g. "start 25m write report".
+type commandMsg string
+
+// readCommands forwards newline-delimited commands from r until EOF.
+func readCommands(r io.Reader, send func(tea.Msg)) {
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		if line := strings.TrimSpace(sc.Text()); line != "" {
+			send(commandMsg(line))
+		}
+	}
+}
+
+func (m model) runCommand(line string) (tea.Model, tea.Cmd) {
+	fields := strings.Fields(line)
+	switch fields[0] {
+	case "start":
+		if len(fields) < 2 {
+			m.err = "start needs a duration, e.g. start 25m focus"
+			return m, nil
+		}
+		input, rest := fields[1], fields[2:]
+		if input == "until" && len(rest) > 0 {
+			input, rest = "until "+rest[0], rest[1:]
+		}
+		return m.startAs(input, strings.Join(rest, " "))
+	case "stop":
+		m, logged := m.recordFinished()
+		return m.reset(), logged
+	case "pause":
+		if m.state == running {
+			return m.pauseOrResume()
+		}
+		return m, nil
+	case "resume":
+		if m.state == paused {
+			return m.pauseOrResume()
+		}
+		return m, nil
+	case "add":
+		// add 5m, or add -5m to take time off.
+		if len(fields) != 2 {
+			m.err = "add needs a duration, e.g. add 5m or add -5m"
+			return m, nil
+		}
+		amount, negative := strings.CutPrefix(fields[1], "-")
+		d, err := parseDuration(amount)
+		if err != nil {
+			m.err = fmt.Sprintf("add: %q is not a duration", fields[1])
+			return m, nil
+		}
+		if negative {
+			d = -d
+		}
+		return m.adjust(d)
+	case "quit":
+		return m, tea.Quit
+	}
+	m.err = fmt.Sprintf("unknown command %q", fields[0])
+	return m, nil
+}
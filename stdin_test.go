@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestCommandAdd(t *testing.T) {
+	tests := []struct {
+		line string
+		left time.Duration
+		err  string
+	}{
+		{"add 5m", 6 * time.Minute, ""},
+		{"add 90", 91 * time.Minute, ""},
+		{"add -30s", 30 * time.Second, ""},
+		{"add", time.Minute, "add needs a duration"},
+		{"add soon", time.Minute, "is not a duration"},
+	}
+	for _, tt := range tests {
+		m, _ := startedModel(t, "1")
+		next, _ := m.runCommand(tt.line)
+		m = next.(model)
+		if m.timeRemaining != tt.left {
+			t.Errorf("%q: left %s, want %s", tt.line, m.timeRemaining, tt.left)
+		}
+		if tt.err == "" && m.err != "" || !strings.Contains(m.err, tt.err) {
+			t.Errorf("%q: error %q, want %q", tt.line, m.err, tt.err)
+		}
+	}
+}
+
+func TestReadCommands(t *testing.T) {
+	var got []string
+	readCommands(strings.NewReader("start 25m write report\n\n  pause \nadd 5m\n"), func(msg tea.Msg) {
+		got = append(got, string(msg.(commandMsg)))
+	})
+	want := []string{"start 25m write report", "pause", "add 5m"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("read %q, want %q", got, want)
+	}
+}
+
+func TestCommandStartRecordsOldLabel(t *testing.T) {
+	m, clk := startedModel(t, "1")
+	m.label = "old"
+	m, _ = m.tick(clk.advance(10 * time.Second))
+	next, _ := m.runCommand("start 5m new")
+	m = next.(model)
+	if m.label != "new" || m.labelTimes["old"] != 10*time.Second || m.labelTimes["new"] != 0 {
+		t.Errorf("label %q, times %v; want new, with 10s recorded for old", m.label, m.labelTimes)
+	}
+}
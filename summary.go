@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func journalFile() string {
+	return filepath.Join(dataDir(), "journal.md")
+}
+
+// appendJournal adds one entry to the journal, a Markdown list kept in
+// the data directory.
+func appendJournal(entry string) tea.Cmd {
+	return func() tea.Msg {
+		if err := os.MkdirAll(dataDir(), 0o755); err != nil {
+			return nil
+		}
+		f, err := os.OpenFile(journalFile(), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil
+		}
+		defer f.Close()
+		fmt.Fprintf(f, "- %s\n", entry)
+		return nil
+	}
+}
+
+// actualTime is how long the session has taken so far, pauses and
+// overtime included.
+func (m model) actualTime() time.Duration {
+	return m.countdown.Duration - m.timeRemaining + m.overrun + m.countdown.PausedFor
+}
+
+// summaryKey handles the keys of the completion screen: restart, break,
+// new timer, quit, and w to write a note.
+func (m model) summaryKey(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "r":
+		m, logged := m.recordFinished()
+		if m.intervals != nil {
+			iv := m.intervals.first()
+			m.intervals = &iv
+			return m.begin(iv.duration()).withWarmup(), logged
+		}
+		return m.begin(m.countdown.Duration).withWarmup(), logged
+	case "b":
+		m, logged := m.recordFinished()
+		m = m.begin(m.breakDuration)
+		m.label = "Break"
+		return m, logged
+	case "n":
+		// The input screen doesn't report progress, so clear the last
+		// timer's taskbar bar or badge rather than leave it at 100%.
+		m, logged := m.recordFinished()
+		return m.reset(), tea.Batch(logged, m.blink(), writeTerminal(m.reporter.clearSequence()))
+	case "q":
+		return m, tea.Quit
+	case "w":
+		ti := textinput.New()
+		ti.Prompt = "Note: "
+		ti.CharLimit = 200
+		ti.Width = m.rowWidth() - len(ti.Prompt) - 1
+		ti.Focus()
+		m.note = &ti
+		return m, m.blink()
+	}
+	return m, nil
+}
+
+// updateNote edits the note; Enter saves it to the journal.
+func (m model) updateNote(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.note = nil
+		return m, nil
+	case tea.KeyEnter:
+		text := strings.TrimSpace(m.note.Value())
+		m.note = nil
+		if text == "" {
+			return m, nil
+		}
+		m.noted = true
+		return m, appendJournal(m.journalEntry(text))
+	}
+	var cmd tea.Cmd
+	ti := *m.note
+	ti, cmd = ti.Update(msg)
+	m.note = &ti
+	return m, cmd
+}
+
+func (m model) journalEntry(note string) string {
+	what := formatDuration(m.countdown.Duration)
+	if m.label != "" {
+		what = m.label + " (" + what + ")"
+	}
+	return fmt.Sprintf("%s %s: %s", m.countdown.Started.Format("2006-01-02 15:04"), what, note)
+}
+
+func (m model) summaryView() string {
+	var s strings.Builder
+	s.WriteString(completedStyle.Render(m.withIcon("Done!")))
+	s.WriteString("\n\n")
+
+	stats := fmt.Sprintf("Planned %s · Actual %s", formatDuration(m.countdown.Duration), formatDuration(m.actualTime()))
+	if m.countdown.Pauses > 0 {
+		stats += fmt.Sprintf(" · Paused %d× (%s)", m.countdown.Pauses, formatDuration(m.countdown.PausedFor))
+	}
+	if m.brokenPauses() {
+		stats += " · " + errorStyle.Render("Broken: over the pause budget")
+	}
+	s.WriteString(stats + "\n\n")
+
+	switch {
+	case m.note != nil:
+		s.WriteString(m.note.View() + "\n")
+		s.WriteString("Enter saves to the journal, Esc cancels\n\n")
+	case m.noted:
+		s.WriteString(lipgloss.NewStyle().Faint(true).Render("Note saved to " + journalFile()))
+		s.WriteString("\n\n")
+	}
+	return s.String()
+}
+
+func (m model) summaryHelp() string {
+	return "r restart · b break · n new timer · w note · q quit"
+}
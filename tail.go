@@ -0,0 +1,292 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const tailInterval = 500 * time.Millisecond
+
+// tailChunk is the most read in one look at the file. When more than
+// that was appended, only the latest progress matters, so the rest is
+// skipped.
+const tailChunk = 256 << 10
+
+// defaultProgressPattern matches a word of a line that is "42%", "42.5%"
+// or "17/250", maybe in brackets or before punctuation. Matching whole
+// words keeps out dates and paths such as 2026/10/16 and /var/log/1/2.
+const defaultProgressPattern = `^[(\[]?(?:(\d+(?:\.\d+)?)%|(\d+)/(\d+))[)\],;:.]?$`
+
+// progressPattern reads how far along a job is from its log lines. A
+// pattern with one group captures a percentage, with two a count and a
+// total; the default allows either. Percentages over 100 and counts over
+// their total are not progress.
+type progressPattern struct {
+	re    *regexp.Regexp
+	words bool // match each word, the last match winning, not the line
+}
+
+func parseProgressPattern(s string) (progressPattern, error) {
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return progressPattern{}, err
+	}
+	if re.NumSubexp() == 0 {
+		return progressPattern{}, fmt.Errorf("%q needs a group for the percentage, or two for x/y", s)
+	}
+	return progressPattern{re: re, words: s == defaultProgressPattern}, nil
+}
+
+// fraction is how far along line says the job is, or false if it doesn't
+// match.
+func (p progressPattern) fraction(line string) (float64, bool) {
+	if !p.words {
+		return p.match(line)
+	}
+	var v float64
+	found := false
+	for _, w := range strings.Fields(line) {
+		if f, ok := p.match(w); ok {
+			v, found = f, true
+		}
+	}
+	return v, found
+}
+
+func (p progressPattern) match(s string) (float64, bool) {
+	groups := p.re.FindStringSubmatch(s)
+	if groups == nil {
+		return 0, false
+	}
+	var set []float64
+	for _, g := range groups[1:] {
+		if g == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(g, 64)
+		if err != nil {
+			return 0, false
+		}
+		set = append(set, v)
+	}
+	switch {
+	case len(set) == 1 && set[0] <= 100:
+		return set[0] / 100, true
+	case len(set) == 2 && set[1] > 0 && set[0] <= set[1]:
+		return set[0] / set[1], true
+	}
+	return 0, false
+}
+
+// tailMsg is what one look at the file found.
+type tailMsg struct {
+	offset   int64
+	carry    []byte // an unfinished last line
+	fraction float64
+	found    bool
+	err      error
+}
+
+// readTail reads what was appended to path since offset, at most
+// tailChunk of it. A file that got shorter was truncated or replaced and
+// is read again from the start.
+func readTail(path string, offset int64, carry []byte, p progressPattern) tailMsg {
+	f, err := os.Open(path)
+	if err != nil {
+		return tailMsg{offset: offset, carry: carry, err: err}
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return tailMsg{offset: offset, carry: carry, err: err}
+	}
+	size := st.Size()
+	if size < offset {
+		offset, carry = 0, nil
+	}
+	skipped := size-offset > tailChunk
+	if skipped {
+		offset, carry = size-tailChunk, nil
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return tailMsg{offset: offset, carry: carry, err: err}
+	}
+	data := make([]byte, size-offset)
+	n, err := io.ReadFull(f, data)
+	if err == io.ErrUnexpectedEOF {
+		// Truncated while reading; the next look starts over.
+		err = nil
+	}
+	data = data[:n]
+	msg := tailMsg{offset: offset + int64(n), err: err}
+	if skipped {
+		// The chunk starts mid-line.
+		data = data[bytes.IndexAny(data, "\r\n")+1:]
+	}
+	data = append(carry, data...)
+	// Progress bars often redraw with \r, so that ends a line too.
+	end := bytes.LastIndexAny(data, "\r\n")
+	if msg.carry = data[end+1:]; len(msg.carry) > tailChunk {
+		// A line this long isn't a progress line.
+		msg.carry = nil
+	}
+	for _, line := range strings.FieldsFunc(string(data[:end+1]), func(r rune) bool { return r == '\n' || r == '\r' }) {
+		if v, ok := p.fraction(line); ok {
+			msg.fraction, msg.found = v, true
+		}
+	}
+	return msg
+}
+
+// tailView shows a job's progress as read from its log.
+type tailView struct {
+	path     string
+	pattern  progressPattern
+	offset   int64
+	carry    []byte
+	start    time.Time
+	now      time.Time
+	fraction float64
+	// The first reading, which the estimate counts from.
+	firstAt       time.Time
+	firstFraction float64
+	seen          bool
+	err           error
+	done          bool
+	bar           progress.Model
+	alerter
+}
+
+func (t tailView) read() tea.Cmd {
+	path, offset, carry, p := t.path, t.offset, t.carry, t.pattern
+	return func() tea.Msg { return readTail(path, offset, carry, p) }
+}
+
+func (t tailView) Init() tea.Cmd {
+	return t.read()
+}
+
+func (t tailView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tailMsg:
+		t.now = time.Now()
+		t.offset, t.carry, t.err = msg.offset, msg.carry, msg.err
+		if msg.found {
+			if !t.seen {
+				t.firstAt, t.firstFraction, t.seen = t.now, msg.fraction, true
+			}
+			t.fraction = msg.fraction
+		}
+		if t.fraction >= 1 {
+			t.done = true
+			return t, t.alert(t.path, "done after "+formatDuration(t.now.Sub(t.start)))
+		}
+		p := t.read()
+		return t, tea.Tick(tailInterval, func(time.Time) tea.Msg { return p() })
+	case tea.KeyMsg:
+		if t.done {
+			return t, tea.Quit
+		}
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return t, tea.Quit
+		}
+	}
+	return t, nil
+}
+
+// eta extrapolates from the progress made since the first reading, or
+// reports false until there is some.
+func (t tailView) eta() (time.Duration, bool) {
+	gained := t.fraction - t.firstFraction
+	if !t.seen || gained <= 0 {
+		return 0, false
+	}
+	spent := t.now.Sub(t.firstAt)
+	return time.Duration(float64(spent) / gained * (1 - t.fraction)), true
+}
+
+func (t tailView) View() string {
+	var s strings.Builder
+	s.WriteString(lipgloss.NewStyle().Bold(true).Render(placeText(t.path, rowWidth)) + "\n\n")
+	elapsed := formatDuration(t.now.Sub(t.start))
+	switch {
+	case t.done:
+		s.WriteString(completedStyle.Render("Done after "+elapsed) + "\n\n")
+	case t.err != nil:
+		s.WriteString(errorStyle.Render(placeText(t.err.Error(), rowWidth)) + "\n\n")
+	case !t.seen:
+		s.WriteString("Waiting for progress in the log...\n\n")
+	default:
+		line := fmt.Sprintf("Elapsed: %s", statusMessageStyle.Render(elapsed))
+		if left, ok := t.eta(); ok {
+			line += fmt.Sprintf(", about %s left (done around %s)", formatDuration(left), t.now.Add(left).Format("15:04"))
+		}
+		s.WriteString(line + "\n\n")
+	}
+	s.WriteString(t.bar.ViewAs(t.fraction) + "\n")
+	if t.done {
+		s.WriteString("\nPress any key to close\n")
+	} else {
+		s.WriteString("\nPress q to stop watching\n")
+	}
+	return lipgloss.NewStyle().Margin(1, 2).Render(s.String())
+}
+
+func runTailCommand(args []string) int {
+	fs := flag.NewFlagSet(appName+" tail", flag.ContinueOnError)
+	pattern := fs.String("pattern", defaultProgressPattern, "regular expression with a group for the percentage, or two for done/total")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), usageLine("tail"))
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitInvalid
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitInvalid
+	}
+	p, err := parseProgressPattern(*pattern)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitInvalid
+	}
+	cfg, err := loadConfig(configFile())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitInvalid
+	}
+
+	th := cfg.theme()
+	setStyles(th)
+	now := time.Now()
+	t := tailView{
+		path:    fs.Arg(0),
+		pattern: p,
+		start:   now,
+		now:     now,
+		bar:     newProgressBar(th).Model,
+		alerter: newAlerter(cfg),
+	}
+	final, err := tea.NewProgram(t, cfg.programOptions()...).Run()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	if !final.(tailView).done {
+		return exitCancelled
+	}
+	return exitCompleted
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDefaultProgressPattern(t *testing.T) {
+	p, err := parseProgressPattern(defaultProgressPattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		line  string
+		want  float64
+		found bool
+	}{
+		{"downloading 42%", 0.42, true},
+		{"[17/250] compiling", 17.0 / 250, true},
+		{"step 1/4 (50%)", 0.5, true},
+		{"12.5%, eta 3m", 0.125, true},
+		{"rotated /var/log/1/2", 0, false},
+		{"built on 2026/10/16", 0, false},
+		{"retry 5/3", 0, false},
+		{"ratio 150%", 0, false},
+		{"sha 100%abc", 0, false},
+		{"nothing here", 0, false},
+	}
+	for _, tt := range tests {
+		got, found := p.fraction(tt.line)
+		if found != tt.found || got != tt.want {
+			t.Errorf("fraction(%q) = %v, %v, want %v, %v", tt.line, got, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestCustomProgressPattern(t *testing.T) {
+	p, err := parseProgressPattern(`done (\d+) of (\d+)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := p.fraction("done 3 of 4 files"); !ok || got != 0.75 {
+		t.Errorf("got %v, %v, want 0.75", got, ok)
+	}
+	if _, err := parseProgressPattern(`\d+%`); err == nil {
+		t.Error("a pattern without groups was accepted")
+	}
+}
+
+func TestReadTail(t *testing.T) {
+	p, _ := parseProgressPattern(defaultProgressPattern)
+	path := filepath.Join(t.TempDir(), "job.log")
+	appendLog := func(s string) {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.WriteString(s)
+		f.Close()
+	}
+
+	appendLog("10%\n20")
+	msg := readTail(path, 0, nil, p)
+	if !msg.found || msg.fraction != 0.1 || string(msg.carry) != "20" || msg.offset != 6 {
+		t.Fatalf("first read: %+v", msg)
+	}
+	appendLog("%\n")
+	msg = readTail(path, msg.offset, msg.carry, p)
+	if !msg.found || msg.fraction != 0.2 || msg.offset != 8 {
+		t.Fatalf("second read: %+v", msg)
+	}
+	msg = readTail(path, msg.offset, msg.carry, p)
+	if msg.found || msg.offset != 8 {
+		t.Fatalf("nothing new: %+v", msg)
+	}
+
+	// Far behind, only the latest chunk is read.
+	appendLog(strings.Repeat("30%\n", tailChunk/4) + "40%\n")
+	msg = readTail(path, 0, nil, p)
+	if !msg.found || msg.fraction != 0.4 {
+		t.Fatalf("skipping ahead: %+v", msg)
+	}
+	if st, _ := os.Stat(path); msg.offset != st.Size() {
+		t.Errorf("offset %d, want %d", msg.offset, st.Size())
+	}
+}
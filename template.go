@@ -0,0 +1,275 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// A template is a named chain of timers with blanks filled in at start,
+// e.g.
+//
+//	meeting: Intro 5m, {n}x Item {i} {m}m, Wrap-up 5m
+//
+// asks for n and m, then runs the intro, n items of m minutes and the
+// wrap-up one after another. A step is an optional count, a label and a
+// duration; {i} in a label is the step's number within its count.
+
+const maxChainSteps = 100
+
+var templateVar = regexp.MustCompile(`\{(\w+)\}`)
+
+type templateStep struct {
+	count    string // empty for once
+	label    string
+	duration string
+}
+
+type timerTemplate struct {
+	slot  int // the template.N it was set from
+	name  string
+	steps []templateStep
+	vars  []string // to ask for, in order of appearance
+}
+
+type templates []timerTemplate
+
+// parseTemplate reads "name: step, step, ...". Durations and counts
+// without blanks are checked here; the rest when they are filled in.
+func parseTemplate(v string) (timerTemplate, error) {
+	name, spec, ok := strings.Cut(v, ":")
+	t := timerTemplate{name: strings.TrimSpace(name)}
+	if !ok || t.name == "" || strings.ContainsAny(t.name, " \t") {
+		return t, fmt.Errorf("%q should look like name: Intro 5m, {n}x Item {m}m", v)
+	}
+	seen := map[string]bool{"i": true}
+	for _, f := range strings.Split(spec, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		var s templateStep
+		if first, rest, ok := strings.Cut(f, " "); ok && (strings.HasSuffix(first, "x") || strings.HasSuffix(first, "×")) {
+			s.count = strings.TrimSuffix(strings.TrimSuffix(first, "x"), "×")
+			f = strings.TrimSpace(rest)
+		}
+		i := strings.LastIndex(f, " ")
+		if i < 0 {
+			return t, fmt.Errorf("%q needs a label and a duration", f)
+		}
+		s.label, s.duration = strings.TrimSpace(f[:i]), f[i+1:]
+		if !templateVar.MatchString(s.duration) {
+			if _, err := parseDuration(s.duration); err != nil {
+				return t, fmt.Errorf("%q: %q is not a duration", f, s.duration)
+			}
+		}
+		if !templateVar.MatchString(s.count) && s.count != "" {
+			if n, err := strconv.Atoi(s.count); err != nil || n < 1 {
+				return t, fmt.Errorf("%q: %q is not a count", f, s.count)
+			}
+		}
+		for _, m := range templateVar.FindAllStringSubmatch(s.count+" "+s.label+" "+s.duration, -1) {
+			if !seen[m[1]] {
+				seen[m[1]] = true
+				t.vars = append(t.vars, m[1])
+			}
+		}
+		t.steps = append(t.steps, s)
+	}
+	if len(t.steps) == 0 {
+		return t, fmt.Errorf("%s has no steps", t.name)
+	}
+	return t, nil
+}
+
+func (ts templates) lookup(name string) (timerTemplate, bool) {
+	for _, t := range ts {
+		if strings.EqualFold(t.name, strings.TrimSpace(name)) {
+			return t, true
+		}
+	}
+	return timerTemplate{}, false
+}
+
+func (ts templates) names() []string {
+	names := make([]string, len(ts))
+	for i, t := range ts {
+		names[i] = t.name
+	}
+	return names
+}
+
+// expand fills in the blanks with values and lays the steps out as a
+// chain.
+func (t timerTemplate) expand(values map[string]string) (chain, error) {
+	fill := func(s string, i int) string {
+		return templateVar.ReplaceAllStringFunc(s, func(v string) string {
+			name := v[1 : len(v)-1]
+			if name == "i" {
+				return strconv.Itoa(i)
+			}
+			return values[name]
+		})
+	}
+	var c chain
+	for _, s := range t.steps {
+		n := 1
+		if s.count != "" {
+			var err error
+			if n, err = strconv.Atoi(fill(s.count, 0)); err != nil || n < 0 {
+				return c, fmt.Errorf("%q is not a count", fill(s.count, 0))
+			}
+		}
+		for i := 1; i <= n; i++ {
+			d, err := parseDuration(fill(s.duration, i))
+			if err != nil {
+				return c, fmt.Errorf("%q is not a duration", fill(s.duration, i))
+			}
+			c.steps = append(c.steps, chainStep{label: fill(s.label, i), duration: d})
+		}
+		if len(c.steps) > maxChainSteps {
+			return c, fmt.Errorf("%s would run more than %d timers", t.name, maxChainSteps)
+		}
+	}
+	if len(c.steps) == 0 {
+		return c, fmt.Errorf("%s has no timers to run", t.name)
+	}
+	return c, nil
+}
+
+// chain is timers run one after another, each under its own label.
+type chain struct {
+	steps []chainStep
+	at    int
+}
+
+type chainStep struct {
+	label    string
+	duration time.Duration
+}
+
+func (c chain) current() chainStep {
+	return c.steps[c.at]
+}
+
+// next returns the step after this one, or false after the last.
+func (c chain) next() (chain, bool) {
+	if c.at+1 >= len(c.steps) {
+		return c, false
+	}
+	c.at++
+	return c, true
+}
+
+// left is the time still planned after the current step.
+func (c chain) left() time.Duration {
+	var d time.Duration
+	for _, s := range c.steps[c.at+1:] {
+		d += s.duration
+	}
+	return d
+}
+
+// startChain runs c from its first step.
+func (m model) startChain(c chain) (model, tea.Cmd) {
+	m, logged := m.recordFinished()
+	m = m.begin(c.current().duration).withWarmup()
+	m.pomodoro, m.intervals = nil, nil
+	m.chain = &c
+	m.label = c.current().label
+	return m, logged
+}
+
+// advanceChain starts the next step after one completes.
+func (m model) advanceChain(next chain) model {
+	m.sessionTotal += m.sittingTime()
+	m.chain = &next
+	m = m.begin(next.current().duration)
+	m.label = next.current().label
+	m.phaseChanged = true
+	return m
+}
+
+// chainView is the step line above the timer, e.g.
+// "Step 2 of 6 · then Item 2 05:00 · 25:00 to go".
+func (m model) chainView() string {
+	c := m.chain
+	line := fmt.Sprintf("Step %d of %d", c.at+1, len(c.steps))
+	if n, ok := c.next(); ok && !m.done {
+		line += lipgloss.NewStyle().Faint(true).Render(fmt.Sprintf(" · then %s %s · %s to go",
+			n.current().label, formatDuration(n.current().duration), formatDuration(c.left()+m.timeRemaining)))
+	}
+	return line
+}
+
+// templateFill asks for a template's blanks one at a time.
+type templateFill struct {
+	template timerTemplate
+	values   map[string]string
+	input    textinput.Model
+}
+
+func (m model) openTemplateFill(t timerTemplate) (tea.Model, tea.Cmd) {
+	if len(t.vars) == 0 {
+		return m.runTemplate(t, nil)
+	}
+	f := &templateFill{template: t, values: map[string]string{}}
+	f.input = f.newInput()
+	m.filling = f
+	return m, m.blink()
+}
+
+func (f *templateFill) newInput() textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = fmt.Sprintf("%s %s: ", f.template.name, f.template.vars[len(f.values)])
+	ti.CharLimit = 20
+	ti.Focus()
+	return ti
+}
+
+func (m model) runTemplate(t timerTemplate, values map[string]string) (tea.Model, tea.Cmd) {
+	c, err := t.expand(values)
+	if err != nil {
+		m.err = err.Error()
+		return m, nil
+	}
+	m.recent = pushRecent(m.recent, t.name)
+	m, logged := m.startChain(c)
+	return m, tea.Batch(logged, saveRecent(m.recent))
+}
+
+// updateFill takes each blank in turn; the last starts the chain. Esc
+// goes back to the input screen.
+func (m model) updateFill(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	f := m.filling
+	switch msg.Type {
+	case tea.KeyCtrlC, tea.KeyEsc:
+		m.filling = nil
+		return m, nil
+	case tea.KeyEnter:
+		v := strings.TrimSpace(f.input.Value())
+		if v == "" {
+			return m, nil
+		}
+		f.values[f.template.vars[len(f.values)]] = v
+		if len(f.values) < len(f.template.vars) {
+			f.input = f.newInput()
+			return m, m.blink()
+		}
+		m.filling = nil
+		return m.runTemplate(f.template, f.values)
+	}
+	var cmd tea.Cmd
+	f.input, cmd = f.input.Update(msg)
+	return m, cmd
+}
+
+func (m model) fillView() string {
+	return m.filling.input.View() + "\n\nEnter for the next value, Esc to cancel\n"
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type progressReporter string
+
+const (
+	reportNone  progressReporter = "off"
+	reportOSC9  progressReporter = "osc9"
+	reportITerm progressReporter = "iterm"
+)
+
+// detectProgressReporter picks the escape sequence flavour for "auto".
+// OSC 9 means "post a notification" to iTerm2 and some others, so the
+// taskbar form is only sent to terminals known to implement it.
+func detectProgressReporter() progressReporter {
+	if os.Getenv("TMUX") != "" || os.Getenv("STY") != "" {
+		return reportNone
+	}
+	switch {
+	case os.Getenv("WT_SESSION") != "", os.Getenv("ConEmuPID") != "":
+		return reportOSC9
+	}
+	switch os.Getenv("TERM_PROGRAM") {
+	case "WezTerm", "ghostty":
+		return reportOSC9
+	case "iTerm.app":
+		return reportITerm
+	}
+	return reportNone
+}
+
+// progressSequence renders the escape sequence showing percent (0-1) and
+// the remaining time in the taskbar or tab badge.
+func (r progressReporter) progressSequence(percent float64, remaining string, paused bool) string {
+	switch r {
+	case reportOSC9:
+		// State 4 shows the bar as paused (yellow in Windows Terminal).
+		state := 1
+		if paused {
+			state = 4
+		}
+		return fmt.Sprintf("\x1b]9;4;%d;%d\x07", state, int(percent*100))
+	case reportITerm:
+		return "\x1b]1337;SetBadgeFormat=" + base64.StdEncoding.EncodeToString([]byte(remaining)) + "\x07"
+	}
+	return ""
+}
+
+func (r progressReporter) clearSequence() string {
+	switch r {
+	case reportOSC9:
+		return "\x1b]9;4;0;0\x07"
+	case reportITerm:
+		return "\x1b]1337;SetBadgeFormat=\x07"
+	}
+	return ""
+}
+
+// writeTerminal sends a non-printing sequence straight to the terminal,
+// bypassing the renderer, which would count it towards line widths.
+func writeTerminal(seq string) tea.Cmd {
+	if seq == "" {
+		return nil
+	}
+	return func() tea.Msg {
+		_, _ = os.Stdout.WriteString(seq)
+		return nil
+	}
+}
+
+func (m model) reportProgress() tea.Cmd {
+	if m.state == inputtingTime || m.countdown.Duration <= 0 {
+		return nil
+	}
+	elapsed := m.countdown.Duration - m.timeRemaining
+	return writeTerminal(m.reporter.progressSequence(
+		float64(elapsed)/float64(m.countdown.Duration),
+		formatDuration(m.timeRemaining),
+		m.state == paused,
+	))
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// isRTL reports whether the first strongly directional character of s
+// belongs to a right-to-left script.
+func isRTL(s string) bool {
+	for _, r := range s {
+		if unicode.In(r, unicode.Arabic, unicode.Hebrew, unicode.Syriac, unicode.Thaana, unicode.Nko) {
+			return true
+		}
+		if unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return false
+}
+
+// fitText shortens s to at most width cells. The ellipsis is appended in
+// logical order, so a bidi-capable terminal shows it on the left of RTL
+// text, where the reader's eye finishes the line.
+func fitText(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	return ansi.Truncate(s, width, "…")
+}
+
+// placeText fits s into a line of the given width, aligned to the start
+// of its reading direction.
+func placeText(s string, width int) string {
+	pos := lipgloss.Left
+	if isRTL(s) {
+		pos = lipgloss.Right
+	}
+	return lipgloss.PlaceHorizontal(width, pos, fitText(s, width))
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"os"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/codytheroux96/progress-timer/timer"
+	"github.com/muesli/termenv"
+)
+
+// theme is what the styles are built from; the model keeps it so the
+// accessibility toggles can rebuild them at runtime.
+type theme struct {
+	scheme              string
+	accent, done, error string
+	barFrom, barTo      string // barTo is empty for a solid bar
+	highContrast        bool
+	reduceMotion        bool
+	asciiBar            bool
+	profile             termenv.Profile
+	bar                 barOptions
+}
+
+// barOptions are the bar.* settings.
+type barOptions struct {
+	fill        string // theme, solid or gradient
+	full, empty rune
+	countDown   bool
+	percent     string // right, inline or hidden
+}
+
+// colorScheme is one of the built-in themes.
+type colorScheme struct {
+	name                string
+	accent, done, error string
+	barFrom, barTo      string
+	highContrast        bool
+}
+
+// colorSchemes are cycled through in this order by the t key. The
+// default's bar is xterm green (256-color 34), which maps onto plain
+// green in 16-color terminals.
+var colorSchemes = []colorScheme{
+	{name: "default", accent: "#FFFF00", done: "#00FF00", error: "#FF0000", barFrom: "#00AF00"},
+	{name: "solarized", accent: "#B58900", done: "#859900", error: "#DC322F", barFrom: "#268BD2", barTo: "#2AA198"},
+	{name: "dracula", accent: "#F1FA8C", done: "#50FA7B", error: "#FF5555", barFrom: "#BD93F9", barTo: "#FF79C6"},
+	{name: "monochrome", accent: "#FFFFFF", done: "#D0D0D0", error: "#8A8A8A", barFrom: "#BCBCBC"},
+	{name: "high-contrast", accent: "#FFFF00", done: "#00FF00", error: "#FF0000", barFrom: "#00AF00", highContrast: true},
+}
+
+func findScheme(name string) (colorScheme, bool) {
+	for _, s := range colorSchemes {
+		if s.name == name {
+			return s, true
+		}
+	}
+	return colorScheme{}, false
+}
+
+func schemeNames() []string {
+	var names []string
+	for _, s := range colorSchemes {
+		names = append(names, s.name)
+	}
+	return names
+}
+
+// withScheme takes the colors of s. The high-contrast scheme turns on
+// high contrast; choosing another doesn't turn it off.
+func (t theme) withScheme(s colorScheme) theme {
+	t.scheme = s.name
+	t.accent, t.done, t.error = s.accent, s.done, s.error
+	t.barFrom, t.barTo = s.barFrom, s.barTo
+	t.highContrast = t.highContrast || s.highContrast
+	return t
+}
+
+func (c config) theme() theme {
+	remote := c.isRemote()
+	s, _ := findScheme(c.scheme)
+	t := theme{
+		highContrast: c.highContrast,
+		reduceMotion: c.reduceMotion || remote,
+		asciiBar:     remote,
+		profile:      colorProfile(c.colorProfile),
+		bar:          c.bar,
+	}.withScheme(s)
+	// Colors set one by one win over the scheme's.
+	if c.accentColor != "" {
+		t.accent = c.accentColor
+	}
+	if c.doneColor != "" {
+		t.done = c.doneColor
+	}
+	if c.errorColor != "" {
+		t.error = c.errorColor
+	}
+	return t
+}
+
+// colorProfile resolves colors.profile. Auto asks the terminal, honouring
+// NO_COLOR, COLORTERM and TERM.
+func colorProfile(setting string) termenv.Profile {
+	switch setting {
+	case "truecolor":
+		return termenv.TrueColor
+	case "256":
+		return termenv.ANSI256
+	case "16":
+		return termenv.ANSI
+	case "none":
+		return termenv.Ascii
+	}
+	return termenv.NewOutput(os.Stdout).EnvColorProfile()
+}
+
+// monochrome reports whether states must be told apart without color,
+// either by choice or because the terminal has none.
+func (t theme) monochrome() bool {
+	return t.highContrast || t.profile == termenv.Ascii
+}
+
+// setStyles builds the shared styles. Colors are degraded to the
+// terminal's palette; without any, and in high contrast, the terminal's
+// own foreground is used and states differ in weight, underline and
+// reverse video instead.
+func setStyles(t theme) {
+	// Attributes are not colors: keep bold and reverse under NO_COLOR.
+	if t.profile == termenv.Ascii {
+		lipgloss.SetColorProfile(termenv.ANSI)
+	} else {
+		lipgloss.SetColorProfile(t.profile)
+	}
+	if t.monochrome() {
+		statusMessageStyle = lipgloss.NewStyle().Bold(true)
+		completedStyle = lipgloss.NewStyle().Bold(true).Reverse(true)
+		errorStyle = lipgloss.NewStyle().Bold(true).Underline(true)
+		overtimeStyle = lipgloss.NewStyle().Bold(true).Reverse(true)
+		return
+	}
+	statusMessageStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.accent)).
+		Bold(true)
+	completedStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.done)).
+		Bold(true)
+	errorStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.error))
+	overtimeStyle = errorStyle.Bold(true)
+}
+
+// newProgressBar builds the bar from the theme and the bar.* settings. A
+// gradient runs to the accent color when the theme has none of its own.
+// Without color the default empty character would look filled, so a dot
+// stands in for it.
+func newProgressBar(t theme) timer.Bar {
+	opts := []progress.Option{
+		progress.WithWidth(barWidth),
+		progress.WithColorProfile(t.profile),
+	}
+	to := t.barTo
+	if to == "" {
+		to = t.accent
+	}
+	switch {
+	case t.monochrome():
+		opts = append(opts, progress.WithSolidFill(""))
+	case t.bar.fill == "gradient" || t.bar.fill == "theme" && t.barTo != "":
+		opts = append(opts, progress.WithGradient(t.barFrom, to))
+	default:
+		opts = append(opts, progress.WithSolidFill(t.barFrom))
+	}
+	full, empty := t.bar.full, t.bar.empty
+	switch {
+	case t.asciiBar:
+		full, empty = '#', '-'
+	case t.monochrome() && empty == '░':
+		empty = '·'
+	}
+	opts = append(opts, progress.WithFillCharacters(full, empty))
+	bar := timer.NewBar(opts...)
+	if t.monochrome() {
+		bar.EmptyColor = ""
+	}
+	bar.Static = t.reduceMotion
+	bar.Drain = t.bar.countDown
+	return bar
+}
+
+// applyTheme rebuilds everything drawn from t. Reduced motion keeps the
+// cursors from blinking.
+func (m model) applyTheme(t theme) model {
+	m.theme = t
+	setStyles(t)
+	m.bar = newProgressBar(t)
+	// The new bar starts where the timer is; the next tick animates it.
+	m.bar, _ = m.bar.Set(m.percent())
+	mode := cursor.CursorBlink
+	if t.reduceMotion {
+		mode = cursor.CursorStatic
+	}
+	m.textInput.Cursor.SetMode(mode)
+	if m.finder != nil {
+		m.finder.input.Cursor.SetMode(mode)
+	}
+	return m
+}
+
+// blink starts the cursor blinking unless motion is reduced.
+func (m model) blink() tea.Cmd {
+	if m.theme.reduceMotion {
+		return nil
+	}
+	return cursor.Blink
+}
+
+// nextScheme switches to the next built-in theme. Leaving high contrast
+// turns it off, so every scheme shows its colors.
+func (m model) nextScheme() (tea.Model, tea.Cmd) {
+	i := 0
+	for j, s := range colorSchemes {
+		if s.name == m.theme.scheme {
+			i = j
+		}
+	}
+	t := m.theme
+	t.highContrast = false
+	return m.applyTheme(t.withScheme(colorSchemes[(i+1)%len(colorSchemes)])), nil
+}
+
+func (m model) toggleHighContrast() (tea.Model, tea.Cmd) {
+	t := m.theme
+	t.highContrast = !t.highContrast
+	return m.applyTheme(t), nil
+}
+
+func (m model) toggleReduceMotion() (tea.Model, tea.Cmd) {
+	t := m.theme
+	t.reduceMotion = !t.reduceMotion
+	m = m.applyTheme(t)
+	return m, m.blink()
+}
@@ -2,15 +2,16 @@ package main
 
 import (
 	"fmt"
-	"os"
 	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/codytheroux96/progress-timer/timer"
 )
 
 type inputState int
@@ -18,55 +19,185 @@ type inputState int
 const (
 	inputtingTime inputState = iota
 	running
+	paused
 )
 
 type model struct {
-	textInput     textinput.Model
-	state         inputState
-	duration      time.Duration
-	timeRemaining time.Duration
-	progress      progress.Model
-	done          bool
-	err           string
+	textInput        textinput.Model
+	labelInput       textinput.Model
+	editingLabel     bool
+	state            inputState
+	label            string
+	session          string
+	sessionTotal     time.Duration
+	countdown        timer.Countdown
+	timeRemaining    time.Duration
+	clock            clock
+	endLayout        string // clock layout of an "until" timer's end time
+	theme            theme
+	bar              timer.Bar
+	icons            iconSet
+	bigDigits        bool
+	rounding         string
+	alwaysHours      bool
+	readoutMode      string
+	project          string
+	viewTemplate     *template.Template
+	placement        placement
+	altScreen        bool
+	reporter         progressReporter
+	agenda           []namedSchedule
+	holidays         holidaySet
+	recent           []string
+	suggestion       int
+	finder           *finder
+	filling          *templateFill // a template's blanks being asked for
+	quickPicks       [10]string
+	presets          presets
+	templates        templates
+	confirmQuit      bool
+	confirming       bool
+	quitDeadline     time.Time
+	exitOnComplete   bool
+	exitDelay        time.Duration
+	inList           bool   // one of several timers in a timerList
+	group            string // the timerList group it belongs to, if any
+	overtime         bool
+	overrun          time.Duration
+	pauseLimit       int // -1 for no limit
+	pauseTimeLimit   time.Duration
+	breakDuration    time.Duration
+	breakTips        []breakTip
+	adjustStep       time.Duration
+	pomodoro         *pomodoro
+	intervals        *intervals
+	chain            *chain
+	warmup           time.Duration
+	warmupLeft       time.Duration
+	onTickCmd        string
+	eventHooks       [numHookEvents]string
+	dryRun           bool
+	tickHookInterval time.Duration
+	lastTickHook     time.Time
+	caldav           caldavClient
+	caldavPush       bool
+	notifier         notifier
+	dispatch         *dispatcher
+	bell             bool
+	player           player
+	soundFile        string
+	repeatAlert      bool
+	escalation       []escalationStep
+	alerting         bool
+	alertSound       string // what the alert plays, louder once escalated
+	alertBody        string // what the alert is about
+	alertingFor      time.Duration
+	done             bool
+	completed        bool // the last timer begun ran to its end; reset keeps it
+	logged           bool
+	startHooked      bool
+	phaseChanged     bool
+	journalID        string
+	estimates        map[string]time.Duration
+	labelTimes       map[string]time.Duration // actual time logged per label
+	note             *textinput.Model
+	noted            bool
+	err              string
+	failures         []deliveryFailure
+	sinks            sinks
+	showFailures     bool
+	width            int
+	height           int
 }
 
 type tickMsg time.Time
 
+// deadlineMsg is a tick timed to land on the deadline, so a timer whose
+// deadline falls between two ticks doesn't finish late.
+type deadlineMsg time.Time
+
+const (
+	barWidth = 40
+	rowWidth = 80
+	// Below this many columns the view drops its side margins.
+	narrowWidth = 40
+)
+
 var (
-	statusMessageStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#FFFF00")).
-				Bold(true)
-	completedStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#00FF00")).
-			Bold(true)
+	statusMessageStyle lipgloss.Style
+	completedStyle     lipgloss.Style
+	errorStyle         lipgloss.Style
+	overtimeStyle      lipgloss.Style
 )
 
-func initialModel() model {
+func initialModel(cfg config) model {
 	ti := textinput.New()
 	ti.Placeholder = "Enter minutes..."
 	ti.Focus()
-	ti.CharLimit = 5
+	ti.CharLimit = 32
 	ti.Width = 20
+	if cfg.defaultMinutes > 0 {
+		ti.SetValue(strconv.Itoa(cfg.defaultMinutes))
+	}
+	li := textinput.New()
+	li.Prompt = "Label: "
+	li.Placeholder = "optional, e.g. write report"
+	li.CharLimit = 80
+	li.Width = 30
 
-	p := progress.New(
-		progress.WithDefaultGradient(),
-		progress.WithWidth(40),
-		progress.WithoutPercentage(),
-		progress.WithSolidFill("green"),
-	)
-
-	return model{
-		textInput: ti,
-		state:     inputtingTime,
-		progress:  p,
+	m := model{
+		textInput:        ti,
+		labelInput:       li,
+		state:            inputtingTime,
+		icons:            cfg.iconSet(),
+		bigDigits:        cfg.bigDigits,
+		rounding:         cfg.rounding,
+		alwaysHours:      cfg.alwaysHours,
+		readoutMode:      cfg.readoutMode,
+		viewTemplate:     cfg.viewTemplate,
+		placement:        cfg.position,
+		altScreen:        cfg.useAltScreen(),
+		reporter:         cfg.reporter,
+		recent:           loadRecent(),
+		clock:            timer.SystemClock{},
+		agenda:           allSchedules(cfg, loadPlan(time.Now())),
+		holidays:         cfg.localHolidays(),
+		suggestion:       -1,
+		quickPicks:       cfg.quickPicks,
+		presets:          cfg.presets,
+		templates:        cfg.templates,
+		estimates:        loadEstimates(),
+		labelTimes:       labelTimes(loadHistory()),
+		confirmQuit:      cfg.confirmQuit,
+		overtime:         cfg.overtime,
+		pauseLimit:       cfg.pauseLimit,
+		pauseTimeLimit:   cfg.pauseTimeLimit,
+		breakDuration:    cfg.breakDuration,
+		breakTips:        cfg.breakTips,
+		adjustStep:       cfg.adjustStep,
+		warmup:           cfg.warmup,
+		notifier:         cfg.notifier(),
+		dispatch:         newDispatcher(cfg.rateLimits),
+		caldav:           cfg.caldav,
+		caldavPush:       cfg.caldavPush,
+		bell:             cfg.soundBell,
+		player:           cfg.player(),
+		soundFile:        cfg.soundFile,
+		repeatAlert:      cfg.soundRepeat,
+		escalation:       cfg.escalation,
+		onTickCmd:        cfg.onTickCmd,
+		eventHooks:       cfg.eventHooks,
+		dryRun:           cfg.dryRun,
+		tickHookInterval: cfg.tickHookInterval,
 	}
+	return m.applyTheme(cfg.theme())
 }
 
 func (m model) Init() tea.Cmd {
 	return tea.Batch(
-		textinput.Blink,
+		m.blink(),
 		tickEverySecond(),
-		tea.EnterAltScreen,
+		m.retryQueued(),
 	)
 }
 
@@ -80,37 +211,150 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
 	switch msg := msg.(type) {
+	case commandMsg:
+		return m.runCommand(string(msg))
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
 	case tea.KeyMsg:
+		// Any key acknowledges a repeating alert.
+		m.alerting = false
+		if m.confirming {
+			return m.updateConfirm(msg)
+		}
+		if m.note != nil {
+			return m.updateNote(msg)
+		}
+		if m.finder != nil {
+			return m.updateFinder(msg)
+		}
+		if m.filling != nil {
+			return m.updateFill(msg)
+		}
+
 		switch msg.Type {
 		case tea.KeyCtrlC, tea.KeyEsc:
-			return m, tea.Quit
+			return m.requestQuit()
+		case tea.KeyCtrlK:
+			return m.openPalette()
+		case tea.KeyCtrlR:
+			if m.state == inputtingTime {
+				return m.openRecentFinder()
+			}
+		case tea.KeyCtrlP:
+			if m.state == inputtingTime && len(m.presets) > 0 {
+				return m.openPresetFinder()
+			}
+		case tea.KeyTab, tea.KeyShiftTab:
+			if m.state == inputtingTime {
+				return m.toggleLabelInput()
+			}
+		case tea.KeyShiftUp, tea.KeyShiftDown:
+			if step := m.dialStep(msg); step != 0 {
+				return m.dial(step), nil
+			}
+		case tea.KeyUp, tea.KeyDown:
+			if m.state == inputtingTime && !m.editingLabel {
+				if step := m.dialStep(msg); step != 0 {
+					return m.dial(step), nil
+				}
+				if n := len(m.suggestions()); n > 0 {
+					if msg.Type == tea.KeyDown {
+						m.suggestion = (m.suggestion + 1) % n
+					} else {
+						m.suggestion = (m.suggestion - 1 + n) % n
+					}
+				}
+				return m, nil
+			}
+		case tea.KeySpace:
+			if m.state != inputtingTime {
+				return m.pauseOrResume()
+			}
+		case tea.KeyRunes:
+			if string(msg.Runes) == "!" && len(m.failures) > 0 && !m.editingLabel {
+				m.showFailures = !m.showFailures
+				return m, nil
+			}
+			if m.state != inputtingTime && string(msg.Runes) == "d" {
+				return m.toggleBigDigits()
+			}
+			if m.state != inputtingTime && string(msg.Runes) == "e" {
+				return m.toggleReadout()
+			}
+			if m.state != inputtingTime && string(msg.Runes) == "t" {
+				return m.nextScheme()
+			}
+			if m.state != inputtingTime && m.done {
+				return m.summaryKey(string(msg.Runes))
+			}
+			if m.state != inputtingTime && string(msg.Runes) == "p" {
+				return m.pauseOrResume()
+			}
+			if r := string(msg.Runes); m.state != inputtingTime && (r == "+" || r == "=" || r == "-") {
+				if r == "-" {
+					return m.adjust(-m.adjustStep)
+				}
+				return m.adjust(m.adjustStep)
+			}
+			if pick := m.quickPick(msg); pick != "" {
+				return m.start(pick)
+			}
+			if step := m.dialStep(msg); step != 0 {
+				return m.dial(step), nil
+			}
+			m.suggestion = -1
 		case tea.KeyEnter:
 			if m.state == inputtingTime {
 				input := strings.TrimSpace(m.textInput.Value())
-				minutes, err := strconv.Atoi(input)
-				if err != nil || minutes <= 0 {
-					m.err = "Please enter a valid positive number"
-					return m, nil
+				if s := m.suggestions(); m.suggestion >= 0 && m.suggestion < len(s) {
+					input = s[m.suggestion]
 				}
-				m.duration = time.Duration(minutes) * time.Minute
-				m.timeRemaining = m.duration
-				m.state = running
-				m.err = ""
-				return m, nil
+				if l := strings.TrimSpace(m.labelInput.Value()); l != "" {
+					m.label = l
+				}
+				return m.start(input)
 			}
+		default:
+			m.suggestion = -1
 		}
 
 	case tickMsg:
-		if m.state == running && m.timeRemaining > 0 {
-			m.timeRemaining -= time.Second
-			if m.timeRemaining <= 0 {
-				m.timeRemaining = 0
-				m.done = true
-			}
+		m, cmd = m.tick(time.Time(msg))
+		if m.exitOnComplete && m.done {
+			return m, cmd
 		}
-		return m, tickEverySecond()
+		return m, tea.Batch(cmd, tickEverySecond(), m.deadlineTick(), m.terminalUpdates())
+
+	case deadlineMsg:
+		m, cmd = m.tick(time.Time(msg))
+		if m.exitOnComplete && m.done {
+			return m, cmd
+		}
+		return m, tea.Batch(cmd, m.terminalUpdates())
+
+	case progress.FrameMsg:
+		m.bar, cmd = m.bar.Update(msg)
+		return m, cmd
+
+	case deliveryErrMsg:
+		m.failures = append(m.failures, deliveryFailure(msg))
 	}
 
+	if m.finder != nil {
+		m.finder.input, cmd = m.finder.input.Update(msg)
+		return m, cmd
+	}
+	if m.filling != nil {
+		m.filling.input, cmd = m.filling.input.Update(msg)
+		return m, cmd
+	}
+	if m.state == inputtingTime && m.editingLabel {
+		m.labelInput, cmd = m.labelInput.Update(msg)
+		return m, cmd
+	}
 	if m.state == inputtingTime {
 		m.textInput, cmd = m.textInput.Update(msg)
 		return m, cmd
@@ -119,72 +363,703 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// tick brings the timer up to now. Remaining time is read off the
+// deadline rather than counted down, so a stalled event loop or a
+// suspended laptop can't make the timer run long. It doesn't schedule the
+// next tick, so a timerList can drive several timers from one clock.
+func (m model) tick(now time.Time) (model, tea.Cmd) {
+	var cmds []tea.Cmd
+	if m.state == running && m.done && m.overtime {
+		m.overrun = max(now.Sub(m.countdown.Deadline).Round(time.Second), 0)
+	}
+	if m.state == running && m.warmupLeft > 0 {
+		var bell tea.Cmd
+		m, bell = m.warmupTick()
+		cmds = append(cmds, bell)
+	} else if m.state == running && !m.done {
+		left := m.countdown.Left(now)
+		// Rounding is only for show: the timer ends at the deadline.
+		m.timeRemaining = timer.Round(left, m.rounding)
+		if left <= 0 {
+			var cmd tea.Cmd
+			m, cmd = m.complete()
+			cmds = append(cmds, cmd)
+		}
+	}
+	m, start := m.startHook()
+	if m.state != inputtingTime && m.startHooked && !m.done {
+		m.publish("tick")
+	}
+	m, hook := m.tickHook(now)
+	m, alert := m.alertTick()
+	m, bar := m.animateBar()
+	return m, tea.Batch(append(cmds, start, hook, alert, bar)...)
+}
+
+// finalSecond is the time left when the deadline comes before the next
+// regular tick, or has just passed.
+func (m model) finalSecond() (time.Duration, bool) {
+	if m.state != running || m.done || m.warmupLeft > 0 {
+		return 0, false
+	}
+	return m.countdown.FinalSecond(m.clock.Now())
+}
+
+func (m model) deadlineTick() tea.Cmd {
+	left, ok := m.finalSecond()
+	if !ok {
+		return nil
+	}
+	return tea.Tick(left, func(t time.Time) tea.Msg {
+		return deadlineMsg(t)
+	})
+}
+
+// percent is the share of the duration that has passed.
+func (m model) percent() float64 {
+	return timer.Fraction(m.countdown.Duration, m.timeRemaining)
+}
+
+// animateBar moves the bar to the time passed. The returned command
+// drives the animation through progress.FrameMsg.
+func (m model) animateBar() (model, tea.Cmd) {
+	if m.state == inputtingTime {
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.bar, cmd = m.bar.Set(m.percent())
+	return m, cmd
+}
+
+// barView draws the animated bar, or a static one when motion is reduced.
+func (m model) barView() string {
+	bar := m.bar
+	bar.Width = m.barWidth()
+	if m.intervals != nil && m.intervals.kind == intervalWork && !m.theme.monochrome() {
+		bar.FullColor = m.theme.error
+	}
+	if bar.Static {
+		// Drawn from the timer rather than the last tick, so an adjustment
+		// shows at once.
+		return bar.ViewAs(m.percent())
+	}
+	return bar.View()
+}
+
+// terminalUpdates mirrors the timer into the window title and taskbar.
+func (m model) terminalUpdates() tea.Cmd {
+	return tea.Batch(pollWindowSize(), m.windowTitle(), m.reportProgress())
+}
+
+// quitGrace is how soon a second Esc or Ctrl+C must follow the first to
+// quit without answering the prompt.
+const quitGrace = 3 * time.Second
+
+// requestQuit asks before abandoning a running timer, so a stray keypress
+// doesn't throw away a long session.
+func (m model) requestQuit() (tea.Model, tea.Cmd) {
+	if !m.confirmQuit || m.state == inputtingTime || m.done {
+		return m, tea.Quit
+	}
+	m.confirming = true
+	m.quitDeadline = m.clock.Now().Add(quitGrace)
+	return m, nil
+}
+
+// inQuitGrace reports whether a second Esc or Ctrl+C would still quit.
+func (m model) inQuitGrace() bool {
+	return m.confirming && m.clock.Now().Before(m.quitDeadline)
+}
+
+// updateConfirm answers the abandon prompt. Pressing Esc or Ctrl+C again
+// within quitGrace confirms as well; later, it is as good as n, so two
+// stray presses far apart don't quit.
+func (m model) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		return m, tea.Quit
+	case "esc", "ctrl+c":
+		if m.inQuitGrace() {
+			return m, tea.Quit
+		}
+	}
+	m.confirming = false
+	return m, nil
+}
+
+func (m model) confirmView() string {
+	name := "timer"
+	if m.label != "" {
+		name = fmt.Sprintf("'%s'", fitText(m.label, 30))
+	}
+	prompt := fmt.Sprintf("Abandon %s with %s remaining? y/n", name, formatDuration(m.timeRemaining))
+	if m.inQuitGrace() {
+		prompt += "\n" + lipgloss.NewStyle().Faint(true).Render("Esc or Ctrl+C again also quits")
+	}
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(errorStyle.GetForeground()).
+		Padding(0, 1).
+		Render(prompt)
+}
+
+// complete marks the timer as finished and returns the follow-up work:
+// syncing the session, then moving to the next pomodoro or interval phase or quitting
+// if --exit-on-complete was given.
+func (m model) complete() (model, tea.Cmd) {
+	m.done = true
+	m.completed = true
+	m.publish("completed")
+	m, alert := m.startAlert()
+	sync := tea.Batch(m.pushSession(), m.desktopNotify(eventComplete.String(), m.completionMessage()), alert, m.eventHook(eventComplete))
+	if m.pomodoro != nil {
+		m, logged := m.recordHistory(statusCompleted)
+		return m.advancePomodoro(), tea.Batch(sync, logged)
+	}
+	if m.intervals != nil {
+		if next, ok := m.intervals.next(); ok {
+			m, logged := m.recordHistory(statusCompleted)
+			return m.advanceIntervals(next), tea.Batch(sync, logged)
+		}
+	}
+	if m.chain != nil {
+		if next, ok := m.chain.next(); ok {
+			m, logged := m.recordHistory(statusCompleted)
+			return m.advanceChain(next), tea.Batch(sync, logged)
+		}
+	}
+	// A timer in overtime keeps counting; it is recorded with its overrun
+	// once it is dismissed.
+	if !m.overtime {
+		var logged tea.Cmd
+		m, logged = m.recordHistory(statusCompleted)
+		sync = tea.Batch(sync, logged)
+	}
+	if m.exitOnComplete {
+		return m, tea.Sequence(sync, m.exitAfterDelay())
+	}
+	return m, sync
+}
+
+func (m model) exitAfterDelay() tea.Cmd {
+	if m.exitDelay <= 0 {
+		return tea.Quit
+	}
+	return tea.Tick(m.exitDelay, func(time.Time) tea.Msg { return tea.Quit() })
+}
+
+// reset abandons the current timer and returns to the input screen.
+func (m model) reset() model {
+	if m.state != inputtingTime && !m.done {
+		m.publish("cancelled")
+	}
+	m.sessionTotal += m.sittingTime()
+	m.state = inputtingTime
+	m.done = false
+	m.confirming = false
+	m.alerting = false
+	m.err = ""
+	m.countdown.Duration = 0
+	m.timeRemaining = 0
+	m.label = m.session
+	m.pomodoro = nil
+	m.intervals = nil
+	m.chain = nil
+	m.textInput.Reset()
+	m.labelInput.Reset()
+	m.suggestion = -1
+	if m.editingLabel {
+		m, _ = m.toggleLabelInput()
+	}
+	return m
+}
+
+// toggleLabelInput moves the cursor between the duration and the label.
+func (m model) toggleLabelInput() (model, tea.Cmd) {
+	m.editingLabel = !m.editingLabel
+	if m.editingLabel {
+		m.textInput.Blur()
+		return m, m.labelInput.Focus()
+	}
+	m.labelInput.Blur()
+	return m, m.textInput.Focus()
+}
+
+// togglePause stops or restarts the countdown; ticks keep arriving while
+// paused but do not count.
+func (m model) togglePause() model {
+	now := m.clock.Now()
+	switch m.state {
+	case running:
+		m.state = paused
+		m.countdown = m.countdown.Pause(now)
+	case paused:
+		m.state = running
+		m.countdown = m.countdown.Resume(now)
+	}
+	return m
+}
+
+// adjust lengthens or shortens the current timer. Taking off more than
+// is left completes it.
+func (m model) adjust(delta time.Duration) (tea.Model, tea.Cmd) {
+	if m.state == inputtingTime || m.done || m.warmupLeft > 0 {
+		return m, nil
+	}
+	delta = max(delta, -m.timeRemaining)
+	m.countdown = m.countdown.Adjust(delta)
+	m.timeRemaining += delta
+	m, journaled := m.journal("adjust", delta.String())
+	if m.timeRemaining > 0 {
+		return m, journaled
+	}
+	if m.state == paused {
+		m, resumed := m.journal("resume", "")
+		m = m.togglePause()
+		journaled = tea.Batch(journaled, resumed)
+	}
+	m, cmd := m.complete()
+	return m, tea.Batch(journaled, cmd)
+}
+
+// pausedTotal is the time spent paused, the current pause included.
+func (m model) pausedTotal() time.Duration {
+	return m.countdown.PausedTotal(m.clock.Now())
+}
+
+// brokenPauses reports whether the timer has gone over the pause budget.
+func (m model) brokenPauses() bool {
+	return m.pauseLimit >= 0 && m.countdown.Pauses > m.pauseLimit ||
+		m.pauseTimeLimit > 0 && m.pausedTotal() > m.pauseTimeLimit
+}
+
+// pauseBudgetView shows what is left of the pause budget, e.g.
+// "(pause 1 of 2, 03:10 of 05:00)".
+func (m model) pauseBudgetView() string {
+	var parts []string
+	if m.pauseLimit >= 0 {
+		parts = append(parts, fmt.Sprintf("pause %d of %d", m.countdown.Pauses, m.pauseLimit))
+	}
+	if m.pauseTimeLimit > 0 {
+		parts = append(parts, fmt.Sprintf("%s of %s", formatDuration(m.pausedTotal()), formatDuration(m.pauseTimeLimit)))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+// clock is where the model reads the time. Everything the countdown
+// computes goes through it, so a fake one can drive the model through
+// its states without waiting.
+type clock = timer.Clock
+
+func wallClock() time.Time {
+	return timer.SystemClock{}.Now()
+}
+
+func (m model) start(input string) (tea.Model, tea.Cmd) {
+	return m.startAs(input, m.label)
+}
+
+// startAs starts input under label. The timer it replaces is recorded
+// first, under its own label.
+func (m model) startAs(input, label string) (tea.Model, tea.Cmd) {
+	typed := input
+	if t, ok := m.templates.lookup(input); ok {
+		return m.openTemplateFill(t)
+	}
+	if p, ok := m.presets.lookup(input); ok {
+		input = p
+		if label == "" {
+			label = strings.TrimSpace(typed)
+		}
+	}
+	d, endLayout, err := parseTimerInput(input, m.clock.Now())
+	if err != nil {
+		m.err = "Please enter minutes, a duration like 1h30m, 90s or 1:30:00, or until 14:30"
+		return m, nil
+	}
+	m, logged := m.recordFinished()
+	m = m.begin(d).withWarmup()
+	m.label = label
+	m.endLayout = endLayout
+	m.pomodoro = nil
+	m.intervals = nil
+	m.chain = nil
+	m.recent = pushRecent(m.recent, typed)
+	return m, tea.Batch(logged, saveRecent(m.recent))
+}
+
+// begin starts counting down d.
+func (m model) begin(d time.Duration) model {
+	m.countdown = timer.Start(d, m.clock.Now())
+	m.timeRemaining = d
+	m.state = running
+	m.bar = newProgressBar(m.theme)
+	m.done = false
+	m.completed = false
+	m.logged = false
+	m.startHooked = false
+	m.journalID = ""
+	m.phaseChanged = false
+	m.overrun = 0
+	m.warmupLeft = 0
+	m.note = nil
+	m.noted = false
+	m.endLayout = ""
+	m.err = ""
+	return m
+}
+
+// quickPick returns the configured duration for a digit typed on an
+// empty input screen.
+func (m model) quickPick(msg tea.KeyMsg) string {
+	if m.state != inputtingTime || m.editingLabel || m.textInput.Value() != "" || len(msg.Runes) != 1 || msg.Paste {
+		return ""
+	}
+	r := msg.Runes[0]
+	if r < '1' || r > '9' {
+		return ""
+	}
+	return m.quickPicks[r-'0']
+}
+
+// dialStep is how far a key moves the duration on the input screen: the
+// adjust step for ↑/↓ and k/j, five times it with Shift or K/J. Plain
+// arrows pick suggestions while there are any, and the letters only dial
+// once the field holds a duration, so preset names can still be typed.
+func (m model) dialStep(msg tea.KeyMsg) time.Duration {
+	if m.state != inputtingTime || m.editingLabel || msg.Paste {
+		return 0
+	}
+	value := strings.TrimSpace(m.textInput.Value())
+	_, err := parseDuration(value)
+	holdsDuration := err == nil
+	step := m.adjustStep
+	switch msg.String() {
+	case "shift+up":
+		return 5 * step
+	case "shift+down":
+		return -5 * step
+	case "up":
+		if len(m.suggestions()) == 0 {
+			return step
+		}
+	case "down":
+		if len(m.suggestions()) == 0 {
+			return -step
+		}
+	case "k":
+		if holdsDuration {
+			return step
+		}
+	case "j":
+		if holdsDuration {
+			return -step
+		}
+	case "K":
+		if holdsDuration {
+			return 5 * step
+		}
+	case "J":
+		if holdsDuration {
+			return -5 * step
+		}
+	}
+	return 0
+}
+
+// dial moves the duration in the field by delta, never below one step.
+// Whole minutes are written the way they are usually typed.
+func (m model) dial(delta time.Duration) model {
+	d, err := parseDuration(m.textInput.Value())
+	if err != nil {
+		d = 0
+	}
+	d = max(d+delta, m.adjustStep)
+	if d%time.Minute == 0 {
+		m.textInput.SetValue(strconv.Itoa(int(d / time.Minute)))
+	} else {
+		m.textInput.SetValue(formatDuration(d))
+	}
+	m.textInput.CursorEnd()
+	m.suggestion = -1
+	m.err = ""
+	return m
+}
+
+func (m model) quickPicksView() string {
+	var picks []string
+	for i, p := range m.quickPicks {
+		if p != "" {
+			picks = append(picks, statusMessageStyle.Render(strconv.Itoa(i))+" "+p)
+		}
+	}
+	return strings.Join(picks, "  ")
+}
+
+func (m model) openRecentFinder() (tea.Model, tea.Cmd) {
+	return m.showFinder(newFinder("Search: ", "Enter to start, Esc to close", m.recent, model.start))
+}
+
+func (m model) updateFinder(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		m.finder = nil
+		return m.requestQuit()
+	case tea.KeyEsc:
+		m.finder = nil
+		return m, nil
+	}
+
+	chosen, cmd := m.finder.update(msg)
+	if chosen == "" {
+		return m, cmd
+	}
+	pick := m.finder.pick
+	m.finder = nil
+	return pick(m, chosen)
+}
+
 func formatDuration(d time.Duration) string {
-	d = d.Round(time.Second)
-	h := d / time.Hour
-	d -= h * time.Hour
-	m := d / time.Minute
-	d -= m * time.Minute
-	s := d / time.Second
+	return timer.Format(d, false)
+}
+
+func (m model) windowTitle() tea.Cmd {
+	if m.state == inputtingTime {
+		return tea.SetWindowTitle(appName)
+	}
+	return tea.SetWindowTitle(m.withIcon(m.readout() + " - " + appName))
+}
+
+func (m model) inOvertime() bool {
+	return m.overtime && m.done
+}
 
-	if h > 0 {
-		return fmt.Sprintf("%02d:%02d:%02d", h, m, s)
+// readout is the main time display: the remaining time, or how far past
+// the end the session has run in overtime mode.
+func (m model) readout() string {
+	switch {
+	case m.inOvertime():
+		return "+" + timer.Format(m.overrun, m.alwaysHours)
+	case m.readoutMode == "elapsed":
+		return timer.Format(m.countdown.Duration-m.timeRemaining, m.alwaysHours)
+	case m.readoutMode == "percent":
+		return fmt.Sprintf("%.0f%%", m.percent()*100)
 	}
-	return fmt.Sprintf("%02d:%02d", m, s)
+	return timer.Format(m.timeRemaining, m.alwaysHours)
+}
+
+// endsAt is the clock time an "until" timer ends, in the layout it was
+// given in. It follows pauses, so it can drift from the time asked for.
+func (m model) endsAt() string {
+	if m.endLayout == "" {
+		return ""
+	}
+	return m.clock.Now().Add(m.timeRemaining).Format(m.endLayout)
+}
+
+// rowWidth is the width available for a line of content inside the
+// margins, capped at the layout's natural width.
+func (m model) rowWidth() int {
+	w := rowWidth
+	if m.width > 0 && m.width-2*m.margin() < w {
+		w = m.width - 2*m.margin()
+	}
+	return max(w, 0)
+}
+
+// margin is the space kept either side of the view.
+func (m model) margin() int {
+	if m.width > 0 && m.width < narrowWidth {
+		return 0
+	}
+	return 2
+}
+
+// barWidth shrinks the bar to leave room for the percentage on the same
+// row.
+func (m model) barWidth() int {
+	if m.theme.bar.percent == "hidden" {
+		return max(min(barWidth, m.rowWidth()), 1)
+	}
+	return max(min(barWidth, m.rowWidth()-len(" 100.0%")), 1)
+}
+
+// alignRight pushes right to the end of a line of the given width, keeping
+// at least one cell between the two parts when they do not fit. Widths are
+// measured in terminal cells, so wide CJK characters and emoji line up.
+func alignRight(left, right string, width int) string {
+	rightWidth := max(width-lipgloss.Width(left), lipgloss.Width(right)+1)
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		left,
+		lipgloss.PlaceHorizontal(rightWidth, lipgloss.Right, right),
+	)
 }
 
 func (m model) View() string {
+	return m.place(m.content())
+}
+
+// content renders the timer without placing it in the window.
+func (m model) content() string {
 	var s strings.Builder
 
-	if m.state == inputtingTime {
-		s.WriteString("\nEnter timer duration in minutes:\n\n")
+	if m.finder != nil {
+		s.WriteString("\n")
+		s.WriteString(m.finder.view())
+	} else if m.filling != nil {
+		s.WriteString("\n")
+		s.WriteString(m.fillView())
+	} else if m.state == inputtingTime {
+		s.WriteString("\nEnter timer duration (minutes, or e.g. 1h30m, 90s, 1:30:00, until 14:30):\n\n")
 		s.WriteString(m.textInput.View())
+		s.WriteString("\n")
+		s.WriteString(m.labelInput.View())
 		s.WriteString("\n\n")
+		if qv := m.quickPicksView(); qv != "" && m.textInput.Value() == "" {
+			s.WriteString(qv)
+			s.WriteString("\n\n")
+		}
+		if len(m.presets) > 0 && m.textInput.Value() == "" {
+			s.WriteString(placeText("Presets: "+strings.Join(m.presets.names(), ", ")+" (Ctrl+P)", m.rowWidth()))
+			s.WriteString("\n\n")
+		}
+		if len(m.templates) > 0 && m.textInput.Value() == "" {
+			s.WriteString(placeText("Templates: "+strings.Join(m.templates.names(), ", "), m.rowWidth()))
+			s.WriteString("\n\n")
+		}
+		sv := m.suggestionsView()
+		if sv != "" {
+			s.WriteString(sv)
+			s.WriteString("\n")
+		}
 		if m.err != "" {
-			s.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Render(m.err + "\n\n"))
+			s.WriteString(errorStyle.Render(placeText(m.err, m.rowWidth())))
+			s.WriteString("\n\n")
+		}
+		if sv != "" {
+			s.WriteString("↑/↓ picks a recent duration, Ctrl+R searches them\n")
+		} else {
+			s.WriteString(fmt.Sprintf("↑/↓ or k/j change the duration by %s, Shift or K/J by %s\n", formatDuration(m.adjustStep), formatDuration(5*m.adjustStep)))
+		}
+		s.WriteString("Press Enter to start, Tab to add a label, Esc to quit, Ctrl+K for commands\n")
+	} else if m.viewTemplate != nil {
+		s.WriteString(m.renderTemplate())
+		if m.confirming {
+			s.WriteString("\n" + m.confirmView())
 		}
-		s.WriteString("Press Enter to start, Esc to quit\n")
 	} else {
-		timeStr := formatDuration(m.timeRemaining)
-		s.WriteString(fmt.Sprintf("\nTime remaining: %s\n\n", statusMessageStyle.Render(timeStr)))
-
-		elapsed := m.duration - m.timeRemaining
-		percentComplete := float64(elapsed) / float64(m.duration)
-
-		m.progress.SetPercent(percentComplete)
-
-		progressBar := m.progress.View()
-		percentage := fmt.Sprintf("%.1f%%", percentComplete*100)
+		if agenda := m.agendaView(m.clock.Now()); agenda != "" {
+			s.WriteString(lipgloss.NewStyle().Faint(true).Render(agenda))
+			s.WriteString("\n")
+		}
+		if m.pomodoro != nil {
+			s.WriteString("\n")
+			s.WriteString(statusMessageStyle.Render(m.pomodoroView()))
+			s.WriteString("\n")
+		}
+		if m.intervals != nil {
+			s.WriteString("\n" + m.intervalsView() + "\n")
+		}
+		if m.chain != nil {
+			s.WriteString("\n" + m.chainView() + "\n")
+		}
+		if plan := m.planBarView(); plan != "" {
+			s.WriteString(plan + "\n")
+		}
+		if tip := m.breakTipView(); tip != "" {
+			s.WriteString(tip + "\n")
+		}
+		if m.label != "" {
+			s.WriteString("\n")
+			s.WriteString(lipgloss.NewStyle().Bold(true).Render(placeText(m.label, m.rowWidth())))
+			s.WriteString("\n")
+		}
+		if m.warmupLeft > 0 {
+			s.WriteString(m.warmupView())
+		} else if big := m.bigReadout(); m.bigDigits && big != "" {
+			s.WriteString("\n" + big + "\n\n")
+		} else if m.inOvertime() {
+			s.WriteString(fmt.Sprintf("\n%s %s\n\n", m.withIcon("Overtime:"), overtimeStyle.Render(m.readout())))
+		} else {
+			s.WriteString(fmt.Sprintf("\n%s %s", m.withIcon(m.readoutLabel()), statusMessageStyle.Render(m.readout())))
+			if end := m.endsAt(); end != "" {
+				s.WriteString(lipgloss.NewStyle().Faint(true).Render(" until " + end))
+			}
+			s.WriteString("\n\n")
+		}
 
-		paddingWidth := 40 - len(percentage)
-		padding := strings.Repeat(" ", paddingWidth)
+		elapsed := m.countdown.Duration - m.timeRemaining
+		progressBar := m.barView()
+		percentage := statusMessageStyle.Render(fmt.Sprintf("%.1f%%", m.percent()*100))
 
-		s.WriteString(progressBar)
-		s.WriteString(padding)
-		s.WriteString(statusMessageStyle.Render(percentage))
+		switch m.theme.bar.percent {
+		case "inline":
+			s.WriteString(progressBar + " " + percentage)
+		case "hidden":
+			s.WriteString(progressBar)
+		default:
+			s.WriteString(alignRight(progressBar, percentage, m.rowWidth()))
+		}
 		s.WriteString("\n\n")
 
 		if m.done {
-			s.WriteString(completedStyle.Render("Done!\n\n"))
+			s.WriteString(m.summaryView())
+		}
+		if m.state == paused {
+			s.WriteString(statusMessageStyle.Render(m.withIcon("PAUSED")))
+			if budget := m.pauseBudgetView(); budget != "" {
+				s.WriteString(" " + budget)
+			}
+			s.WriteString("\n\n")
+		}
+		if m.brokenPauses() && !m.logged {
+			s.WriteString(errorStyle.Render("Pause budget used up: this timer will be logged as broken"))
+			s.WriteString("\n\n")
 		}
 
 		s.WriteString(fmt.Sprintf("Elapsed: %s / Total: %s\n",
 			formatDuration(elapsed),
-			formatDuration(m.duration)))
+			formatDuration(m.countdown.Duration)))
 		s.WriteString(fmt.Sprintf("Seconds: %.0f / %.0f\n\n",
 			elapsed.Seconds(),
-			m.duration.Seconds()))
+			m.countdown.Duration.Seconds()))
+		if m.session != "" {
+			s.WriteString(fmt.Sprintf("Session total: %s\n\n", formatDuration(m.sessionTotal+m.sittingTime())))
+		}
+		if ev := m.estimateView(); ev != "" {
+			s.WriteString(ev + "\n\n")
+		}
 
-		s.WriteString("Press Esc to quit\n")
+		if m.err != "" {
+			s.WriteString(errorStyle.Render(placeText(m.err, m.rowWidth())))
+			s.WriteString("\n")
+		}
+		if m.dryRun {
+			s.WriteString(lipgloss.NewStyle().Faint(true).Render(placeText("Dry run: notifications, calendar pushes and hooks are only logged", m.rowWidth())) + "\n\n")
+		}
+		if badge := m.failureBadge(); badge != "" {
+			s.WriteString(badge + "\n")
+			if m.showFailures {
+				s.WriteString(m.failuresView() + "\n")
+			}
+			s.WriteString("\n")
+		}
+		if m.confirming {
+			s.WriteString(m.confirmView())
+			s.WriteString("\n")
+		} else if m.done && m.note == nil {
+			s.WriteString(m.summaryHelp() + "\n")
+		} else if m.state == paused {
+			s.WriteString("Press Space to resume, Esc to quit, Ctrl+K for commands\n")
+		} else if m.inList {
+			s.WriteString("Press Space to pause, n for another timer, Esc to quit, Ctrl+K for commands\n")
+		} else {
+			s.WriteString("Press Space to pause, Esc to quit, Ctrl+K for commands\n")
+		}
 	}
 
-	return lipgloss.NewStyle().Margin(1, 2).Render(s.String())
-}
-
-func main() {
-	p := tea.NewProgram(initialModel())
-	if _, err := p.Run(); err != nil {
-		fmt.Printf("Error running program: %v", err)
-		os.Exit(1)
-	}
+	return lipgloss.NewStyle().Margin(1, m.margin()).Render(s.String())
 }
@@ -0,0 +1,60 @@
+package timer
+
+import (
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Bar is a countdown's progress bar. It fills as time passes, or with
+// Drain empties, and glides between readings unless Static.
+type Bar struct {
+	progress.Model
+	Static bool
+	Drain  bool
+	at     float64
+}
+
+// NewBar returns a bar built from opts, without the percentage.
+func NewBar(opts ...progress.Option) Bar {
+	return Bar{Model: progress.New(append([]progress.Option{progress.WithoutPercentage()}, opts...)...)}
+}
+
+func (b Bar) fill(fraction float64) float64 {
+	if b.Drain {
+		return 1 - fraction
+	}
+	return fraction
+}
+
+// Set moves the bar to fraction of the time passed. The command, if any,
+// drives the animation through progress.FrameMsg.
+func (b Bar) Set(fraction float64) (Bar, tea.Cmd) {
+	b.at = fraction
+	if b.Static {
+		return b, nil
+	}
+	return b, b.Model.SetPercent(b.fill(fraction))
+}
+
+// Update moves the animation on. Frames meant for another bar are
+// ignored.
+func (b Bar) Update(msg tea.Msg) (Bar, tea.Cmd) {
+	m, cmd := b.Model.Update(msg)
+	b.Model = m.(progress.Model)
+	return b, cmd
+}
+
+// View draws the bar where the animation has got to, or for a static
+// bar where it was last Set.
+func (b Bar) View() string {
+	if b.Static {
+		return b.ViewAs(b.at)
+	}
+	return b.Model.View()
+}
+
+// ViewAs draws the bar at fraction of the time passed, without
+// animating.
+func (b Bar) ViewAs(fraction float64) string {
+	return b.Model.ViewAs(b.fill(fraction))
+}
@@ -0,0 +1,136 @@
+package timer
+
+import (
+	"fmt"
+	"time"
+)
+
+// Clock is where a Model reads the time, so a fake one can drive it
+// through its states without waiting.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock reads the wall clock without its monotonic reading.
+// Monotonic clocks stop while the machine sleeps, so deadlines compare
+// wall time.
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now().Round(0)
+}
+
+// Countdown is the timing of a countdown: when it started, when it ends
+// and the pauses in between. It is a value that is given the time rather
+// than reading it, so any clock can drive it.
+type Countdown struct {
+	Duration  time.Duration
+	Started   time.Time
+	Deadline  time.Time     // the wall-clock end, moved on by pauses
+	PausedAt  time.Time     // zero unless paused
+	PausedFor time.Duration // the finished pauses
+	Pauses    int
+}
+
+// Start begins counting down d at now.
+func Start(d time.Duration, now time.Time) Countdown {
+	return Countdown{Duration: d, Started: now, Deadline: now.Add(d)}
+}
+
+func (c Countdown) Paused() bool {
+	return !c.PausedAt.IsZero()
+}
+
+// Pause stops the countdown at now. Pausing a paused countdown does
+// nothing.
+func (c Countdown) Pause(now time.Time) Countdown {
+	if !c.Paused() {
+		c.PausedAt = now
+		c.Pauses++
+	}
+	return c
+}
+
+// Resume restarts the countdown, moving the deadline on by the pause.
+func (c Countdown) Resume(now time.Time) Countdown {
+	if c.Paused() {
+		c.PausedFor += now.Sub(c.PausedAt)
+		c.Deadline = c.Deadline.Add(now.Sub(c.PausedAt))
+		c.PausedAt = time.Time{}
+	}
+	return c
+}
+
+// PausedTotal is the time spent paused, the current pause included.
+func (c Countdown) PausedTotal(now time.Time) time.Duration {
+	if c.Paused() {
+		return c.PausedFor + now.Sub(c.PausedAt)
+	}
+	return c.PausedFor
+}
+
+// Left is the time until the deadline, as of the pause if paused. It is
+// negative once the deadline has passed.
+func (c Countdown) Left(now time.Time) time.Duration {
+	if c.Paused() {
+		now = c.PausedAt
+	}
+	return c.Deadline.Sub(now)
+}
+
+// Adjust lengthens the countdown by delta, or shortens it if negative.
+func (c Countdown) Adjust(delta time.Duration) Countdown {
+	c.Duration += delta
+	c.Deadline = c.Deadline.Add(delta)
+	return c
+}
+
+// FinalSecond is the time left when the deadline comes before the next
+// once-a-second tick, or has just passed, so a tick can be timed to land
+// on it rather than up to a second late.
+func (c Countdown) FinalSecond(now time.Time) (time.Duration, bool) {
+	if c.Paused() {
+		return 0, false
+	}
+	left := c.Deadline.Sub(now)
+	return max(left, 0), left < time.Second
+}
+
+// Round rounds the time left to whole seconds: to the nearest, "up" so
+// 00:00 means done, or "down" so the readout never shows more than is
+// left.
+func Round(left time.Duration, rounding string) time.Duration {
+	switch rounding {
+	case "up":
+		left = (left + time.Second - 1).Truncate(time.Second)
+	case "down":
+		left = left.Truncate(time.Second)
+	default:
+		left = left.Round(time.Second)
+	}
+	return max(left, 0)
+}
+
+// Format shows d as MM:SS, or HH:MM:SS when there are hours or hours is
+// set.
+func Format(d time.Duration, hours bool) string {
+	d = d.Round(time.Second)
+	h := d / time.Hour
+	d -= h * time.Hour
+	m := d / time.Minute
+	d -= m * time.Minute
+	s := d / time.Second
+
+	if h > 0 || hours {
+		return fmt.Sprintf("%02d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%02d:%02d", m, s)
+}
+
+// Fraction is the share of d that has passed with left to go.
+func Fraction(d, left time.Duration) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return float64(d-left) / float64(d)
+}
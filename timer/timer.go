@@ -0,0 +1,178 @@
+// Package timer is the countdown behind progress-timer: a deadline-based
+// Countdown with pause and resume, the Bar it is drawn with, and Model,
+// the two together as a Bubble Tea component.
+//
+// Embed a Model in your own model, start it from Init and pass it every
+// message:
+//
+//	t := timer.New(timer.Options{Duration: 25 * time.Minute})
+//	cmd := t.Init()
+//	...
+//	next, cmd := m.timer.Update(msg)
+//	m.timer = next.(timer.Model)
+//
+// A DoneMsg carrying the timer's ID is sent when it reaches zero.
+package timer
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Options configure a new Model. Only Duration is required.
+type Options struct {
+	Duration time.Duration
+	// Clock is the system clock if nil.
+	Clock Clock
+	// Rounding is how the time left is shown: "nearest" (the default),
+	// "up" or "down". The timer ends at its deadline whichever it is.
+	Rounding string
+	// AlwaysHours shows HH:MM:SS even under an hour.
+	AlwaysHours bool
+	// Bar is drawn instead of a plain one 40 cells wide.
+	Bar *Bar
+}
+
+// TickMsg brings a timer up to date. Timers ignore ticks that aren't
+// theirs, so any number can run side by side.
+type TickMsg struct {
+	ID    int
+	Time  time.Time
+	tag   int
+	final bool // timed to the deadline; it doesn't schedule another
+}
+
+// DoneMsg is sent once when the timer with ID reaches its deadline.
+type DoneMsg struct {
+	ID int
+}
+
+var lastID atomic.Int64
+
+// Model is a countdown drawn as the time left over a bar. It implements
+// tea.Model, so it can also run as a program of its own. The zero value
+// is not usable; call New.
+type Model struct {
+	Countdown Countdown
+	Bar       Bar
+	id        int
+	tag       int // ticks of an earlier run carry an older tag
+	clock     Clock
+	rounding  string
+	hours     bool
+	left      time.Duration
+	done      bool
+}
+
+// New returns a timer counting down from now.
+func New(o Options) Model {
+	if o.Clock == nil {
+		o.Clock = SystemClock{}
+	}
+	bar := NewBar(progress.WithWidth(40), progress.WithSolidFill("#00AF00"))
+	if o.Bar != nil {
+		bar = *o.Bar
+	}
+	return Model{
+		Countdown: Start(o.Duration, o.Clock.Now()),
+		Bar:       bar,
+		id:        int(lastID.Add(1)),
+		clock:     o.Clock,
+		rounding:  o.Rounding,
+		hours:     o.AlwaysHours,
+		left:      o.Duration,
+	}
+}
+
+// ID tells this timer's messages from another's.
+func (m Model) ID() int {
+	return m.id
+}
+
+// Remaining is the time left, rounded for show.
+func (m Model) Remaining() time.Duration {
+	return m.left
+}
+
+func (m Model) Done() bool {
+	return m.done
+}
+
+// Init starts the ticks.
+func (m Model) Init() tea.Cmd {
+	return m.tick()
+}
+
+func (m Model) tick() tea.Cmd {
+	id, tag := m.id, m.tag
+	cmd := tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return TickMsg{ID: id, Time: t, tag: tag}
+	})
+	if left, ok := m.Countdown.FinalSecond(m.clock.Now()); ok {
+		final := tea.Tick(left, func(t time.Time) tea.Msg {
+			return TickMsg{ID: id, Time: t, tag: tag, final: true}
+		})
+		cmd = tea.Batch(cmd, final)
+	}
+	return cmd
+}
+
+// Update handles the timer's ticks and its bar's animation frames.
+// Ticks keep arriving while paused but do not count.
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case TickMsg:
+		if msg.ID != m.id || msg.tag != m.tag || m.done {
+			return m, nil
+		}
+		now := m.clock.Now()
+		left := m.Countdown.Left(now)
+		// Rounding is only for show: the timer ends at the deadline.
+		m.left = Round(left, m.rounding)
+		var bar tea.Cmd
+		m.Bar, bar = m.Bar.Set(Fraction(m.Countdown.Duration, m.left))
+		if left <= 0 {
+			m.done = true
+			id := m.id
+			return m, tea.Batch(bar, func() tea.Msg { return DoneMsg{ID: id} })
+		}
+		if msg.final {
+			return m, bar
+		}
+		return m, tea.Batch(bar, m.tick())
+	case progress.FrameMsg:
+		var cmd tea.Cmd
+		m.Bar, cmd = m.Bar.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// Toggle pauses the timer or resumes it.
+func (m Model) Toggle() Model {
+	now := m.clock.Now()
+	if m.Countdown.Paused() {
+		m.Countdown = m.Countdown.Resume(now)
+	} else if !m.done {
+		m.Countdown = m.Countdown.Pause(now)
+	}
+	return m
+}
+
+// Restart counts the timer's duration down again from now.
+func (m Model) Restart() (Model, tea.Cmd) {
+	m.Countdown = Start(m.Countdown.Duration, m.clock.Now())
+	m.left = m.Countdown.Duration
+	m.done = false
+	m.tag++
+	var bar tea.Cmd
+	m.Bar, bar = m.Bar.Set(0)
+	return m, tea.Batch(bar, m.tick())
+}
+
+func (m Model) View() string {
+	return Format(m.left, m.hours) + "\n" + m.Bar.View()
+}
@@ -0,0 +1,128 @@
+package timer
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time          { return c.now }
+func (c *fakeClock) advance(d time.Duration) { c.now = c.now.Add(d) }
+
+var start = time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+func TestCountdownPause(t *testing.T) {
+	c := Start(10*time.Minute, start)
+	c = c.Pause(start.Add(2 * time.Minute))
+	c = c.Pause(start.Add(3 * time.Minute)) // already paused
+	if left := c.Left(start.Add(6 * time.Minute)); left != 8*time.Minute {
+		t.Errorf("left while paused %s, want 8m0s", left)
+	}
+	c = c.Resume(start.Add(7 * time.Minute))
+	if c.Pauses != 1 || c.PausedTotal(start.Add(8*time.Minute)) != 5*time.Minute {
+		t.Errorf("pauses %d for %s, want 1 for 5m0s", c.Pauses, c.PausedTotal(start.Add(8*time.Minute)))
+	}
+	if want := start.Add(15 * time.Minute); !c.Deadline.Equal(want) {
+		t.Errorf("deadline %s, want %s", c.Deadline, want)
+	}
+	c = c.Adjust(-time.Minute)
+	if c.Duration != 9*time.Minute || c.Left(start.Add(7*time.Minute)) != 7*time.Minute {
+		t.Errorf("after adjusting: %s with %s left", c.Duration, c.Left(start.Add(7*time.Minute)))
+	}
+}
+
+func TestFinalSecond(t *testing.T) {
+	c := Start(10*time.Second, start)
+	if _, ok := c.FinalSecond(start.Add(8 * time.Second)); ok {
+		t.Error("final second two seconds early")
+	}
+	if left, ok := c.FinalSecond(start.Add(9*time.Second + 300*time.Millisecond)); !ok || left != 700*time.Millisecond {
+		t.Errorf("got %s, %v, want 700ms", left, ok)
+	}
+	if left, ok := c.FinalSecond(start.Add(11 * time.Second)); !ok || left != 0 {
+		t.Errorf("past the deadline: got %s, %v, want 0", left, ok)
+	}
+	if _, ok := c.Pause(start).FinalSecond(start.Add(9 * time.Second)); ok {
+		t.Error("final second while paused")
+	}
+}
+
+func TestRoundAndFormat(t *testing.T) {
+	tests := []struct {
+		left     time.Duration
+		rounding string
+		want     string
+	}{
+		{1400 * time.Millisecond, "nearest", "00:01"},
+		{1400 * time.Millisecond, "up", "00:02"},
+		{1600 * time.Millisecond, "down", "00:01"},
+		{-time.Second, "up", "00:00"},
+		{time.Hour - 400*time.Millisecond, "nearest", "01:00:00"},
+	}
+	for _, tt := range tests {
+		if got := Format(Round(tt.left, tt.rounding), false); got != tt.want {
+			t.Errorf("%s rounded %s = %q, want %q", tt.left, tt.rounding, got, tt.want)
+		}
+	}
+	if got := Format(59*time.Second, true); got != "00:00:59" {
+		t.Errorf("Format(59s, true) = %q, want %q", got, "00:00:59")
+	}
+}
+
+func TestModel(t *testing.T) {
+	clk := &fakeClock{start}
+	m := New(Options{Duration: 3 * time.Second, Clock: clk, Rounding: "up"})
+	other := New(Options{Duration: time.Second, Clock: clk})
+	update := func(msg tea.Msg) tea.Cmd {
+		next, cmd := m.Update(msg)
+		m = next.(Model)
+		return cmd
+	}
+
+	clk.advance(1500 * time.Millisecond)
+	update(TickMsg{ID: other.ID(), Time: clk.now})
+	if m.Remaining() != 3*time.Second {
+		t.Errorf("another timer's tick counted: %s left", m.Remaining())
+	}
+	update(TickMsg{ID: m.ID(), Time: clk.now})
+	if m.Remaining() != 2*time.Second || m.View()[:5] != "00:02" {
+		t.Errorf("got %s left, view %q", m.Remaining(), m.View())
+	}
+
+	m = m.Toggle()
+	clk.advance(time.Hour)
+	update(TickMsg{ID: m.ID(), Time: clk.now})
+	if m.Done() || m.Remaining() != 2*time.Second {
+		t.Errorf("paused timer moved: %s left", m.Remaining())
+	}
+	m = m.Toggle()
+
+	clk.advance(1500 * time.Millisecond)
+	cmd := update(TickMsg{ID: m.ID(), Time: clk.now})
+	if !m.Done() || m.Remaining() != 0 {
+		t.Fatalf("not done at the deadline: %s left", m.Remaining())
+	}
+	var done bool
+	for _, msg := range cmd().(tea.BatchMsg) {
+		if msg == nil {
+			continue
+		}
+		if d, ok := msg().(DoneMsg); ok && d.ID == m.ID() {
+			done = true
+		}
+	}
+	if !done {
+		t.Error("no DoneMsg")
+	}
+
+	old := m.tag
+	m, _ = m.Restart()
+	clk.advance(time.Second)
+	update(TickMsg{ID: m.ID(), Time: clk.now, tag: old})
+	if m.Done() || m.Remaining() != 3*time.Second {
+		t.Errorf("after restart: done %v, %s left", m.Done(), m.Remaining())
+	}
+}
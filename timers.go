@@ -0,0 +1,379 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/codytheroux96/progress-timer/timer"
+)
+
+const listBarWidth = 20
+
+// timerList runs several timers side by side. n adds a timer while the
+// focused one runs, PgDn and PgUp move focus, as do Tab and Shift+Tab
+// except on an input screen, where they reach the label field. Esc on an
+// added timer's input screen closes it. The first timer is the one started
+// from the command line and carries the session.
+//
+// g names the group the focused timer belongs to, and timers added with n
+// join the focused timer's group. A group is listed together under a
+// header, and P pauses or resumes all of it.
+type timerList struct {
+	cfg      config
+	setup    timerSetup
+	timers   []model
+	focus    int
+	grouping *textinput.Model // the group name being typed, if any
+	review   *review          // the end-of-day review, while it is open
+	reviewed string           // the day the review was last shown
+}
+
+// timerSetup is what the command line sets on every timer, the first as
+// well as any added with n.
+type timerSetup struct {
+	project      string
+	readoutMode  string // remembered for the project; empty for the config's
+	overtime     bool
+	onTickCmd    string
+	tickInterval time.Duration
+	sinks        sinks
+	dispatch     *dispatcher // shared, so the delivery limits hold across timers
+}
+
+func (s timerSetup) newTimer(cfg config) model {
+	m := initialModel(cfg)
+	m.project = s.project
+	if s.readoutMode != "" {
+		m.readoutMode = s.readoutMode
+	}
+	m.overtime = m.overtime || s.overtime
+	if s.onTickCmd != "" {
+		m.onTickCmd = s.onTickCmd
+	}
+	if s.tickInterval != 0 {
+		m.tickHookInterval = s.tickInterval
+	}
+	m.sinks = s.sinks
+	if s.dispatch != nil {
+		m.dispatch = s.dispatch
+	}
+	return m
+}
+
+func newTimerList(cfg config, setup timerSetup, first model) timerList {
+	first.inList = true
+	l := timerList{cfg: cfg, setup: setup, timers: []model{first}, reviewed: reviewedOn()}
+	return l.openReview(first.clock.Now())
+}
+
+// openReview opens the end-of-day review if it is due.
+func (l timerList) openReview(now time.Time) timerList {
+	if l.review == nil && reviewDue(l.cfg.reviewTime, now, l.reviewed) {
+		r := newReview(now, l.focused().rowWidth())
+		l.review = &r
+	}
+	return l
+}
+
+func (l timerList) focused() model { return l.timers[l.focus] }
+
+func (l timerList) Init() tea.Cmd {
+	if l.review != nil {
+		return tea.Batch(l.focused().Init(), textinput.Blink)
+	}
+	return l.focused().Init()
+}
+
+func (l timerList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tickMsg:
+		// One clock drives every timer; the focused one owns the terminal.
+		cmds := []tea.Cmd{tickEverySecond()}
+		for i := range l.timers {
+			var cmd tea.Cmd
+			l.timers[i], cmd = l.timers[i].tick(time.Time(msg))
+			cmds = append(cmds, cmd, l.timers[i].deadlineTick())
+		}
+		if l.review == nil {
+			if l = l.openReview(l.focused().clock.Now()); l.review != nil {
+				cmds = append(cmds, textinput.Blink)
+			}
+		}
+		return l, tea.Batch(append(cmds, l.focused().terminalUpdates())...)
+
+	case deadlineMsg:
+		// One timer's deadline; ticking the others early does no harm.
+		var cmds []tea.Cmd
+		for i := range l.timers {
+			var cmd tea.Cmd
+			l.timers[i], cmd = l.timers[i].tick(time.Time(msg))
+			cmds = append(cmds, cmd)
+		}
+		return l, tea.Batch(append(cmds, l.focused().terminalUpdates())...)
+
+	case progress.FrameMsg:
+		// Each bar ignores frames that carry another bar's ID.
+		var cmds []tea.Cmd
+		for i := range l.timers {
+			var cmd tea.Cmd
+			l.timers[i].bar, cmd = l.timers[i].bar.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+		return l, tea.Batch(cmds...)
+
+	case tea.WindowSizeMsg:
+		for i := range l.timers {
+			l.timers[i].width, l.timers[i].height = msg.Width, msg.Height
+		}
+		return l, nil
+
+	case tea.KeyMsg:
+		for i := range l.timers {
+			l.timers[i].alerting = false
+		}
+		if l.grouping != nil {
+			return l.updateGrouping(msg)
+		}
+		if l.review != nil && msg.Type != tea.KeyCtrlC {
+			r, cmd, done := l.review.update(msg)
+			l.review = &r
+			if done {
+				l.review, l.reviewed = nil, r.day.Format(time.DateOnly)
+			}
+			return l, cmd
+		}
+		f := l.focused()
+		if f.confirming || f.note != nil || f.finder != nil {
+			break
+		}
+		// On an input screen Tab belongs to the label field.
+		multi, tab := len(l.timers) > 1, f.state != inputtingTime
+		switch {
+		case msg.String() == "n" && f.state != inputtingTime && !f.done:
+			return l.add()
+		case msg.String() == "g" && f.state != inputtingTime:
+			ti := textinput.New()
+			ti.Prompt = "Group: "
+			ti.Placeholder = "e.g. cooking; empty for none"
+			ti.CharLimit = 40
+			ti.SetValue(f.group)
+			ti.Focus()
+			l.grouping = &ti
+			return l, textinput.Blink
+		case msg.String() == "P" && f.state != inputtingTime && f.group != "":
+			return l.pauseGroup(f.group)
+		case multi && (msg.Type == tea.KeyPgDown || tab && msg.Type == tea.KeyTab):
+			return l.moveFocus(1)
+		case multi && (msg.Type == tea.KeyPgUp || tab && msg.Type == tea.KeyShiftTab):
+			return l.moveFocus(-1)
+		case msg.Type == tea.KeyEsc && f.state == inputtingTime && l.focus > 0:
+			l.timers = slices.Delete(l.timers, l.focus, l.focus+1)
+			l.focus = min(l.focus, len(l.timers)-1)
+			return l, nil
+		}
+	}
+
+	next, cmd := l.focused().Update(msg)
+	l.timers[l.focus] = next.(model)
+	if _, key := msg.(tea.KeyMsg); l.review != nil && !key {
+		// The reflection's cursor blinks too.
+		var blink tea.Cmd
+		l.review.reflection, blink = l.review.reflection.Update(msg)
+		cmd = tea.Batch(cmd, blink)
+	}
+	return l, cmd
+}
+
+// add opens a new timer on its input screen and focuses it. It gets the
+// same setup as the first timer but not its session.
+func (l timerList) add() (tea.Model, tea.Cmd) {
+	first := l.timers[0]
+	t := l.setup.newTimer(l.cfg)
+	t.inList = true
+	t.group = l.focused().group
+	t.width, t.height = first.width, first.height
+	l.timers = append(l.timers, t)
+	l.focus = len(l.timers) - 1
+	return l, t.blink()
+}
+
+// order is the order the timers are listed in: as they were added, but
+// with each group together where its first timer is.
+func (l timerList) order() []int {
+	var out []int
+	placed := map[string]bool{}
+	for i, t := range l.timers {
+		switch {
+		case t.group == "":
+			out = append(out, i)
+		case !placed[t.group]:
+			placed[t.group] = true
+			out = append(out, l.members(t.group)...)
+		}
+	}
+	return out
+}
+
+func (l timerList) members(group string) []int {
+	var out []int
+	for i, t := range l.timers {
+		if t.group == group {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// moveFocus moves the focus by step timers in the order they are listed.
+func (l timerList) moveFocus(step int) (tea.Model, tea.Cmd) {
+	order := l.order()
+	at := slices.Index(order, l.focus)
+	l.focus = order[(at+step+len(order))%len(order)]
+	return l, l.focused().blink()
+}
+
+func (l timerList) updateGrouping(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return l, tea.Quit
+	case tea.KeyEsc:
+		l.grouping = nil
+		return l, nil
+	case tea.KeyEnter:
+		l.timers[l.focus].group = strings.TrimSpace(l.grouping.Value())
+		l.grouping = nil
+		return l, nil
+	}
+	ti, cmd := l.grouping.Update(msg)
+	l.grouping = &ti
+	return l, cmd
+}
+
+// pauseGroup pauses every running timer in group, or if none is running
+// resumes the paused ones.
+func (l timerList) pauseGroup(group string) (tea.Model, tea.Cmd) {
+	members := l.members(group)
+	pause := slices.ContainsFunc(members, func(i int) bool { return l.timers[i].state == running && !l.timers[i].done })
+	var cmds []tea.Cmd
+	for _, i := range members {
+		t := l.timers[i]
+		if pause && (t.state != running || t.done) || !pause && t.state != paused {
+			continue
+		}
+		next, cmd := t.pauseOrResume()
+		l.timers[i] = next.(model)
+		cmds = append(cmds, cmd)
+	}
+	return l, tea.Batch(cmds...)
+}
+
+// View shows the focused timer in full under a compact row per timer.
+// A lone timer looks the same as outside a list.
+func (l timerList) View() string {
+	f := l.focused()
+	if l.review != nil {
+		// Timers carry on underneath; the review covers them until done.
+		return f.place(lipgloss.NewStyle().Margin(1, f.margin()).Render(l.review.view()))
+	}
+	if len(l.timers) == 1 && l.grouping == nil {
+		return f.View()
+	}
+
+	var rows []string
+	group := ""
+	for _, i := range l.order() {
+		t := l.timers[i]
+		if t.group != "" && t.group != group {
+			rows = append(rows, l.groupRow(t.group))
+		}
+		group = t.group
+		rows = append(rows, t.listRow(i+1, i == l.focus))
+	}
+	if l.grouping != nil {
+		rows = append(rows, "", l.grouping.View())
+	} else {
+		rows = append(rows, "", "PgDn/PgUp or Tab switches timers, Esc on a new timer's input closes it",
+			"g groups the focused timer, P pauses or resumes its group")
+	}
+	list := lipgloss.NewStyle().Margin(1, f.margin(), 0).Render(strings.Join(rows, "\n"))
+	return f.place(lipgloss.JoinVertical(lipgloss.Left, list, f.content()))
+}
+
+// groupRow is a group's header: the progress of its timers together and
+// the one due to finish first.
+func (l timerList) groupRow(group string) string {
+	var planned, left, nextLeft time.Duration
+	next := ""
+	n, done, started := 0, 0, 0
+	for _, i := range l.members(group) {
+		t := l.timers[i]
+		n++
+		if t.state == inputtingTime {
+			continue
+		}
+		started++
+		planned += t.countdown.Duration
+		left += t.timeRemaining
+		switch {
+		case t.done:
+			done++
+		case t.state == running && (next == "" || t.timeRemaining < nextLeft):
+			next, nextLeft = t.listName(i+1), t.timeRemaining
+		}
+	}
+	status := "not started"
+	switch {
+	case next != "":
+		status = fmt.Sprintf("next: %s in %s", next, formatDuration(nextLeft))
+	case started > 0 && done == started:
+		status = completedStyle.Render("all done")
+	case started > 0:
+		status = "paused"
+	}
+	head := lipgloss.NewStyle().Bold(true).Render(placeText(group, 16)) + fmt.Sprintf(" %d timers", n)
+	share := timer.Fraction(planned, left)
+	bar := l.focused().bar
+	bar.Width = max(min(listBarWidth, l.focused().rowWidth()-lipgloss.Width(head+status)-10), 5)
+	return head + " " + bar.ViewAs(share) + fmt.Sprintf(" %3.0f%%  ", share*100) + status
+}
+
+// listName is what a timer is called in the list: its label, or its
+// place in the list.
+func (m model) listName(n int) string {
+	if m.label != "" {
+		return m.label
+	}
+	return fmt.Sprintf("Timer %d", n)
+}
+
+// listRow is the compact one-line form of a timer, indented under its
+// group's header if it has one.
+func (m model) listRow(n int, focused bool) string {
+	marker := "  "
+	if focused {
+		marker = "> "
+	}
+	if m.group != "" {
+		marker = "  " + marker
+	}
+	name := placeText(m.listName(n), 16)
+	if m.state == inputtingTime {
+		return marker + name + " " + lipgloss.NewStyle().Faint(true).Render("not started")
+	}
+
+	status := m.readout()
+	if m.state == paused {
+		status += " (paused)"
+	} else if m.done && !m.inOvertime() {
+		status = completedStyle.Render("done")
+	}
+	bar := m.bar
+	bar.Width = max(min(listBarWidth, m.rowWidth()-lipgloss.Width(marker+name+status)-3), 5)
+	return marker + name + " " + bar.ViewAs(m.percent()) + "  " + status
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestTimerListTab(t *testing.T) {
+	first, _ := startedModel(t, "25")
+	var l tea.Model = newTimerList(defaultConfig(), timerSetup{}, first)
+	press := func(k tea.KeyMsg) timerList {
+		l, _ = l.Update(k)
+		return l.(timerList)
+	}
+
+	tl := press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if len(tl.timers) != 2 || tl.focus != 1 {
+		t.Fatalf("n: %d timers, focus %d; want 2, 1", len(tl.timers), tl.focus)
+	}
+	tl = press(tea.KeyMsg{Type: tea.KeyTab})
+	if tl.focus != 1 || !tl.timers[1].editingLabel {
+		t.Errorf("Tab on the input screen: focus %d, editing label %v; want 1, true", tl.focus, tl.timers[1].editingLabel)
+	}
+	tl = press(tea.KeyMsg{Type: tea.KeyPgUp})
+	if tl.focus != 0 {
+		t.Errorf("PgUp: focus %d, want 0", tl.focus)
+	}
+	tl = press(tea.KeyMsg{Type: tea.KeyTab})
+	if tl.focus != 1 {
+		t.Errorf("Tab on a running timer: focus %d, want 1", tl.focus)
+	}
+}
+
+// recordSink keeps what it is sent.
+type recordSink struct{ events *[]progressEvent }
+
+func (s recordSink) send(e progressEvent) { *s.events = append(*s.events, e) }
+func (s recordSink) close()               {}
+
+func TestTimerListAddSetup(t *testing.T) {
+	isolate(t)
+	var events []progressEvent
+	setup := timerSetup{
+		project:      "book",
+		readoutMode:  "percent",
+		overtime:     true,
+		onTickCmd:    "true",
+		tickInterval: time.Minute,
+		sinks:        sinks{recordSink{&events}},
+	}
+	cfg := defaultConfig()
+	l := newTimerList(cfg, setup, setup.newTimer(cfg))
+	next, _ := l.add()
+	added := next.(timerList).timers[1]
+	if added.project != "book" || added.readoutMode != "percent" || !added.overtime ||
+		added.onTickCmd != "true" || added.tickHookInterval != time.Minute {
+		t.Errorf("added timer: project %q, readout %q, overtime %v, on-tick %q every %s; want the setup's",
+			added.project, added.readoutMode, added.overtime, added.onTickCmd, added.tickHookInterval)
+	}
+	if len(added.sinks) != 1 {
+		t.Fatalf("added timer has %d sinks, want 1", len(added.sinks))
+	}
+}
+
+func TestTimerListGroups(t *testing.T) {
+	isolate(t)
+	first, _ := startedModel(t, "25")
+	var l tea.Model = newTimerList(defaultConfig(), timerSetup{}, first)
+	press := func(keys ...tea.KeyMsg) timerList {
+		for _, k := range keys {
+			l, _ = l.Update(k)
+		}
+		return l.(timerList)
+	}
+	runes := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+
+	// An ungrouped second timer, then the first put in a group and a third
+	// added to it.
+	press(runes("n"), runes("5"), enter, tea.KeyMsg{Type: tea.KeyPgUp})
+	press(runes("g"), runes("cooking"), enter)
+	tl := press(runes("n"), runes("10"), enter)
+	if got := []string{tl.timers[0].group, tl.timers[1].group, tl.timers[2].group}; got[0] != "cooking" || got[1] != "" || got[2] != "cooking" {
+		t.Fatalf("groups %q, want cooking, none, cooking", got)
+	}
+	if order := tl.order(); order[0] != 0 || order[1] != 2 || order[2] != 1 {
+		t.Errorf("order %v, want the group together: [0 2 1]", order)
+	}
+	if tl = press(tea.KeyMsg{Type: tea.KeyPgDown}); tl.focus != 1 {
+		t.Errorf("PgDn from the group's last timer: focus %d, want 1", tl.focus)
+	}
+	if header := tl.groupRow("cooking"); !strings.Contains(header, "2 timers") || !strings.Contains(header, "next: Timer 3 in 10:00") {
+		t.Errorf("header %q, want 2 timers with Timer 3 next", header)
+	}
+
+	tl = press(tea.KeyMsg{Type: tea.KeyPgUp}, runes("P"))
+	if tl.timers[0].state != paused || tl.timers[2].state != paused || tl.timers[1].state != running {
+		t.Errorf("P paused %v, %v, %v; want the group only", tl.timers[0].state, tl.timers[1].state, tl.timers[2].state)
+	}
+	if header := tl.groupRow("cooking"); !strings.Contains(header, "paused") {
+		t.Errorf("header %q, want paused", header)
+	}
+	tl = press(runes("P"))
+	if tl.timers[0].state != running || tl.timers[2].state != running {
+		t.Errorf("second P left %v, %v; want both running", tl.timers[0].state, tl.timers[2].state)
+	}
+}
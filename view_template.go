@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/codytheroux96/progress-timer/timer"
+)
+
+// viewData is what a user-defined view_template can reference.
+type viewData struct {
+	Remaining string
+	Elapsed   string
+	Total     string
+	Bar       string
+	Percent   string
+	Label     string
+	Icon      string
+	Done      bool
+	Paused    bool
+	Overtime  string
+	Phase     string
+	Ends      string
+}
+
+func parseViewTemplate(text string) (*template.Template, error) {
+	return template.New("view").Option("missingkey=error").Parse(text)
+}
+
+func (m model) viewData() viewData {
+	elapsed := m.countdown.Duration - m.timeRemaining
+
+	d := viewData{
+		Remaining: timer.Format(m.timeRemaining, m.alwaysHours),
+		Elapsed:   formatDuration(elapsed),
+		Total:     formatDuration(m.countdown.Duration),
+		Bar:       m.barView(),
+		Percent:   fmt.Sprintf("%.1f%%", m.percent()*100),
+		Label:     m.label,
+		Icon:      m.stateIcon(),
+		Done:      m.done,
+		Paused:    m.state == paused,
+		Ends:      m.endsAt(),
+	}
+	if m.inOvertime() {
+		d.Overtime = m.readout()
+	}
+	if m.pomodoro != nil {
+		d.Phase = m.pomodoroView()
+	}
+	if m.intervals != nil {
+		d.Phase = m.intervalsView()
+	}
+	return d
+}
+
+func (m model) renderTemplate() string {
+	var b strings.Builder
+	if err := m.viewTemplate.Execute(&b, m.viewData()); err != nil {
+		return errorStyle.Render(fmt.Sprintf("view_template: %v", err))
+	}
+	return b.String()
+}
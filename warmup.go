@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// withWarmup holds the countdown back for the configured get-ready
+// period. It applies to timers the user starts, not to phase changes.
+func (m model) withWarmup() model {
+	m.warmupLeft = m.warmup.Round(time.Second)
+	return m
+}
+
+// warmupTick counts the lead-in down by a second, ringing the terminal
+// bell, if sound.bell allows, for each count and once more as the timer
+// starts.
+func (m model) warmupTick() (model, tea.Cmd) {
+	m.warmupLeft -= time.Second
+	if m.warmupLeft <= 0 {
+		m.warmupLeft = 0
+		m.countdown.Started = m.clock.Now()
+		m.countdown.Deadline = m.countdown.Started.Add(m.timeRemaining)
+	}
+	if !m.bell {
+		return m, nil
+	}
+	return m, writeTerminal("\a")
+}
+
+func (m model) warmupView() string {
+	return fmt.Sprintf("\nGet ready… %s\n\n", statusMessageStyle.Render(fmt.Sprint(int(m.warmupLeft.Seconds()))))
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// exitedMsg reports that the watched process has finished.
+type exitedMsg struct {
+	code     int
+	noStatus bool  // not our child, so its status can't be known
+	stopped  bool  // killed by a signal
+	err      error // set when the command couldn't run at all
+}
+
+// watchView times an external process: a stopwatch, or with an expected
+// duration a bar and an estimate of when it will finish.
+type watchView struct {
+	wait     func() exitedMsg
+	stop     func() // nil when q should only stop watching
+	stopping bool   // q stopped the command
+	name     string
+	start    time.Time
+	expect   time.Duration
+	now      time.Time
+	exited   *exitedMsg
+	bar      progress.Model
+	alerter
+}
+
+// alerter sends the completion alerts of the watch and tail views.
+type alerter struct {
+	notifier notifier
+	player   player
+	sound    string
+	bell     bool
+	hook     string // hooks.on_complete
+	dryRun   bool
+	dispatch *dispatcher
+}
+
+func newAlerter(cfg config) alerter {
+	return alerter{
+		notifier: cfg.notifier(),
+		player:   cfg.player(),
+		sound:    cfg.soundFile,
+		bell:     cfg.soundBell,
+		hook:     cfg.eventHooks[eventComplete],
+		dryRun:   cfg.dryRun,
+		dispatch: newDispatcher(cfg.rateLimits),
+	}
+}
+
+// alert goes out on every channel the config enables. The complete hook
+// gets TIMER_EVENT=complete, the title as TIMER_LABEL and the body as
+// TIMER_RESULT.
+func (a alerter) alert(title, body string) tea.Cmd {
+	var cmds []tea.Cmd
+	switch {
+	case a.hook != "" && a.dryRun:
+		cmds = append(cmds, a.dispatch.dryRun("Hook "+eventComplete.String(), "", a.hook))
+	case a.hook != "":
+		c := shellCommand(a.hook)
+		c.Env = append(os.Environ(), "TIMER_EVENT="+eventComplete.String(), "TIMER_LABEL="+title, "TIMER_RESULT="+body)
+		cmds = append(cmds, a.dispatch.runHook("Hook "+eventComplete.String(), "", c))
+	}
+	if a.bell {
+		cmds = append(cmds, writeTerminal("\a"))
+	}
+	if a.player != nil && a.sound != "" {
+		p, file := a.player, a.sound
+		cmds = append(cmds, func() tea.Msg {
+			_ = p.play(file)
+			return nil
+		})
+	}
+	if a.notifier != nil {
+		n := a.notifier
+		if a.dryRun {
+			cmds = append(cmds, a.dispatch.dryRun("Desktop notification", "", title+": "+body))
+		} else {
+			cmds = append(cmds, a.dispatch.deliver("Desktop notification", "", notifyTimeout, func() error { return n.notify(title, body) }))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+func (w watchView) Init() tea.Cmd {
+	wait := w.wait
+	return tea.Batch(tickEverySecond(), func() tea.Msg { return wait() })
+}
+
+// runChild runs c to the end.
+func runChild(c *exec.Cmd) exitedMsg {
+	err := c.Run()
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		if exit.ExitCode() < 0 {
+			return exitedMsg{code: exitStopped, stopped: true}
+		}
+		return exitedMsg{code: exit.ExitCode()}
+	}
+	if err != nil {
+		return exitedMsg{code: exitError, err: err}
+	}
+	return exitedMsg{}
+}
+
+func (w watchView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tickMsg:
+		if w.exited == nil {
+			w.now = time.Time(msg)
+			return w, tickEverySecond()
+		}
+	case exitedMsg:
+		w.now = time.Now()
+		w.exited = &msg
+		return w, w.alert(w.name, w.result())
+	case tea.KeyMsg:
+		if w.exited != nil {
+			return w, tea.Quit
+		}
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			if w.stop == nil {
+				return w, tea.Quit
+			}
+			// The command is stopped and its exit reported as usual.
+			w.stopping = true
+			w.stop()
+		}
+	}
+	return w, nil
+}
+
+// result describes how the command ended, e.g. "exited with status 1
+// after 02:10".
+func (w watchView) result() string {
+	took := formatDuration(w.now.Sub(w.start))
+	switch {
+	case w.exited.err != nil:
+		return fmt.Sprintf("could not run: %v", w.exited.err)
+	case w.exited.stopped:
+		return "stopped after " + took
+	case w.exited.noStatus:
+		return "exited after " + took
+	case w.exited.code == 0:
+		return "succeeded after " + took
+	}
+	return fmt.Sprintf("exited with status %d after %s", w.exited.code, took)
+}
+
+func (w watchView) View() string {
+	elapsed := w.now.Sub(w.start).Round(time.Second)
+	var s strings.Builder
+	s.WriteString(lipgloss.NewStyle().Bold(true).Render(placeText(w.name, rowWidth)) + "\n\n")
+	if w.exited != nil {
+		style := completedStyle
+		if w.exited.code != 0 || w.exited.err != nil {
+			style = errorStyle
+		}
+		s.WriteString(style.Render(w.result()) + "\n\nPress any key to close\n")
+		return lipgloss.NewStyle().Margin(1, 2).Render(s.String())
+	}
+
+	s.WriteString(fmt.Sprintf("Elapsed: %s\n\n", statusMessageStyle.Render(formatDuration(elapsed))))
+	if w.expect > 0 {
+		if elapsed <= w.expect {
+			eta := w.start.Add(w.expect)
+			s.WriteString(fmt.Sprintf("Expected done at %s, %s left\n", eta.Format("15:04"), formatDuration(w.expect-elapsed)))
+		} else {
+			s.WriteString(overtimeStyle.Render("Over the expected time by "+formatDuration(elapsed-w.expect)) + "\n")
+		}
+		s.WriteString(w.bar.ViewAs(min(float64(elapsed)/float64(w.expect), 1)) + "\n")
+	}
+	if w.stop != nil {
+		s.WriteString("\nPress q to stop the command\n")
+	} else {
+		s.WriteString("\nPress q to stop watching\n")
+	}
+	return lipgloss.NewStyle().Margin(1, 2).Render(s.String())
+}
+
+func runWatchCommand(args []string) int {
+	fs := flag.NewFlagSet(appName+" watch", flag.ContinueOnError)
+	expect := fs.Duration("expect", 0, "how long the command usually takes, to show a bar and an estimate")
+	dry := fs.Bool("dry-run", false, "log the notification instead of sending it")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), usageLine("watch"))
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitInvalid
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitInvalid
+	}
+	cfg, err := loadConfig(configFile())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitInvalid
+	}
+	cfg.dryRun = *dry
+
+	// Output is held back until the view closes, so it can't tear
+	// through the screen. Only the end of it is kept.
+	out := &tailBuffer{max: watchOutputLimit}
+	c := exec.Command(fs.Arg(0), fs.Args()[1:]...)
+	c.Stdout, c.Stderr = out, out
+
+	w := newWatchView(cfg, strings.Join(fs.Args(), " "), *expect)
+	w.wait = func() exitedMsg { return runChild(c) }
+	w.stop = func() {
+		if c.Process != nil {
+			_ = c.Process.Kill()
+		}
+	}
+	code := runWatch(cfg, w)
+	kept := out.bytes()
+	if out.dropped > 0 {
+		fmt.Printf("[%d bytes of output before this were dropped]\n", out.dropped)
+	}
+	os.Stdout.Write(kept)
+	return code
+}
+
+// watchOutputLimit is how much of a watched command's output is kept.
+const watchOutputLimit = 1 << 20
+
+// tailBuffer keeps the last max bytes written to it.
+type tailBuffer struct {
+	max     int
+	buf     []byte
+	dropped int64
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.buf = append(b.buf, p...)
+	// Trimming only at twice the limit keeps the copying linear.
+	if len(b.buf) > 2*b.max {
+		b.trim()
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) trim() {
+	if over := len(b.buf) - b.max; over > 0 {
+		b.dropped += int64(over)
+		b.buf = append(b.buf[:0], b.buf[over:]...)
+	}
+}
+
+// bytes returns what is kept: the last max bytes.
+func (b *tailBuffer) bytes() []byte {
+	b.trim()
+	return b.buf
+}
+
+func newWatchView(cfg config, name string, expect time.Duration) watchView {
+	t := cfg.theme()
+	setStyles(t)
+	now := time.Now()
+	return watchView{
+		name:    name,
+		start:   now,
+		expect:  expect,
+		now:     now,
+		bar:     newProgressBar(t).Model,
+		alerter: newAlerter(cfg),
+	}
+}
+
+// runWatch shows w until the process exits and the view is closed, and
+// returns the process's exit status.
+func runWatch(cfg config, w watchView) int {
+	final, err := tea.NewProgram(w, cfg.programOptions()...).Run()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	w = final.(watchView)
+	if w.exited == nil {
+		return exitCancelled
+	}
+	if w.stopping {
+		return exitStopped
+	}
+	if w.exited.err != nil {
+		fmt.Fprintln(os.Stderr, w.exited.err)
+	}
+	return w.exited.code
+}
+
+// watchPID watches a process that is already running, such as a build
+// started before anyone thought to time it.
+func watchPID(cfg config, pid int) int {
+	if err := findPID(pid); err != nil {
+		fmt.Fprintf(os.Stderr, "process %d: %v\n", pid, err)
+		return exitInvalid
+	}
+	w := newWatchView(cfg, fmt.Sprintf("Process %d", pid), 0)
+	w.wait = func() exitedMsg { return waitPID(pid) }
+	return runWatch(cfg, w)
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestTailBuffer(t *testing.T) {
+	b := &tailBuffer{max: 10}
+	for i := range 100 {
+		b.Write([]byte{byte('a' + i%26)})
+	}
+	if got := string(b.bytes()); got != "mnopqrstuv" || b.dropped != 90 {
+		t.Errorf("kept %q with %d dropped, want mnopqrstuv with 90", got, b.dropped)
+	}
+}
+
+func TestWatchStopped(t *testing.T) {
+	w := watchView{stop: func() {}}
+	next, _ := w.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	if w = next.(watchView); !w.stopping {
+		t.Error("q didn't mark the command as stopped")
+	}
+}
+
+func TestRunChild(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no sh")
+	}
+	if got := runChild(exec.Command("sh", "-c", "exit 2")); got.code != 2 || got.stopped {
+		t.Errorf("exit 2: got %+v", got)
+	}
+	if got := runChild(exec.Command("sh", "-c", "kill -9 $$")); got.code != exitStopped || !got.stopped {
+		t.Errorf("killed: got %+v, want stopped", got)
+	}
+	if got := runChild(exec.Command("no-such-command-" + strings.Repeat("x", 8))); got.err == nil || got.code != exitError {
+		t.Errorf("missing command: got %+v", got)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const minDayWidth = 12
+
+// weekView shows a week of schedules and planned blocks as columns,
+// Monday first. Left and right move between weeks.
+type weekView struct {
+	schedules []namedSchedule
+	holidays  holidaySet
+	monday    time.Time
+	width     int
+}
+
+func newWeekView(schedules []namedSchedule, holidays holidaySet, now time.Time) weekView {
+	y, mo, d := now.Date()
+	offset := (int(now.Weekday()) + 6) % 7
+	return weekView{
+		schedules: schedules,
+		holidays:  holidays,
+		monday:    time.Date(y, mo, d-offset, 0, 0, 0, 0, now.Location()),
+		width:     rowWidth,
+	}
+}
+
+func (w weekView) Init() tea.Cmd { return nil }
+
+func (w weekView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		w.width = msg.Width
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "left", "h":
+			w.monday = w.monday.AddDate(0, 0, -7)
+		case "right", "l":
+			w.monday = w.monday.AddDate(0, 0, 7)
+		case "t":
+			w = newWeekView(w.schedules, w.holidays, time.Now())
+		case "q", "esc", "ctrl+c":
+			return w, tea.Quit
+		}
+	}
+	return w, nil
+}
+
+// blocks lists the triggers on the day starting at midnight, in order.
+func (w weekView) blocks(midnight time.Time) []upcoming {
+	var out []upcoming
+	for _, u := range upcomingSchedules(w.schedules, w.holidays, midnight.Add(-time.Nanosecond)) {
+		if u.at.Before(midnight.AddDate(0, 0, 1)) {
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+func (w weekView) View() string {
+	now := time.Now()
+	dayWidth := max((w.width-4)/7, minDayWidth)
+	faint := lipgloss.NewStyle().Faint(true)
+
+	var columns []string
+	var total time.Duration
+	for i := range 7 {
+		day := w.monday.AddDate(0, 0, i)
+		header := day.Format("Mon 2")
+		if day.Format(time.DateOnly) == now.Format(time.DateOnly) {
+			header = statusMessageStyle.Render(header)
+		} else {
+			header = lipgloss.NewStyle().Bold(true).Render(header)
+		}
+		lines := []string{header, ""}
+		for _, u := range w.blocks(day) {
+			total += u.sc.duration
+			block := fmt.Sprintf("%s %s", u.at.Format("15:04"), formatDuration(u.sc.duration))
+			if u.sc.label != "" {
+				block += "\n" + fitText(u.sc.label, dayWidth-1)
+			}
+			if u.at.Add(u.sc.duration).Before(now) {
+				block = faint.Render(block)
+			}
+			lines = append(lines, block, "")
+		}
+		columns = append(columns, lipgloss.NewStyle().Width(dayWidth).Render(strings.Join(lines, "\n")))
+	}
+
+	var s strings.Builder
+	s.WriteString(fmt.Sprintf("Week of %s · %s planned\n\n", w.monday.Format("Jan 2, 2006"), formatDuration(total)))
+	s.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, columns...))
+	s.WriteString("\n←/→ change week, t this week, q quit\n")
+	return lipgloss.NewStyle().Margin(1, 2).Render(s.String())
+}
+
+func runWeekView(cfg config, holidays holidaySet, now time.Time) int {
+	setStyles(cfg.theme())
+	w := newWeekView(allSchedules(cfg, loadPlan(now)), holidays, now)
+	if _, err := tea.NewProgram(w, cfg.programOptions()...).Run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	return 0
+}